@@ -0,0 +1,1397 @@
+package mcaf
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/aws/aws-sdk-go/service/account"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/servicecatalog"
+	"golang.org/x/time/rate"
+)
+
+const (
+	fakeRootID              = "r-root"
+	fakeManagementAccountID = "000000000000"
+	fakeProductID           = "prod-accountfactory"
+	fakeArtifactIDActive    = "pa-active"
+	fakeArtifactIDOld       = "pa-old"
+)
+
+// fakeJoinedTimestamp is the time all fake accounts joined the organization.
+var fakeJoinedTimestamp = time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+// fakeAWS is an in-memory fake of the Organizations, Service Catalog, CloudFormation
+// and CodeBuild APIs used by the provider, served over HTTP so the real AWS SDK
+// clients can be pointed at it using custom endpoints.
+type fakeAWS struct {
+	mu     sync.Mutex
+	server *httptest.Server
+
+	// pageSize is the number of items returned per page by paginated operations.
+	pageSize int
+
+	// latency is added to every request, to simulate the round trip to AWS.
+	latency time.Duration
+
+	// failRecords maps account names to the error description of a failed
+	// provisioning record, to simulate failing Account Factory runs.
+	failRecords map[string]string
+
+	// errors maps operations to the error code returned when they are called. Errors
+	// for a single resource can be injected using "Operation/ID" as the key.
+	errors map[string]string
+
+	// requests counts the requests per operation.
+	requests map[string]int
+
+	// hooks maps operations to a function called on every request for the operation.
+	hooks map[string]func()
+
+	// accessKeys maps operations to the access key ID the last request was signed with.
+	accessKeys map[string]string
+
+	// tags maps resource IDs to their tags.
+	tags map[string]map[string]string
+
+	// alternateContacts maps account IDs and contact types, e.g. 123456789012/BILLING,
+	// to the alternate contacts.
+	alternateContacts map[string]*account.AlternateContact
+
+	nextID              int
+	products            map[string]*fakeProduct
+	ous                 map[string]*fakeOU
+	accounts            map[string]*fakeAccount
+	provisionedProducts map[string]*fakeProvisionedProduct
+	records             map[string]*fakeRecord
+	builds              []*codebuild.StartBuildInput
+}
+
+type fakeOU struct {
+	id       string
+	name     string
+	parentID string
+}
+
+type fakeAccount struct {
+	id       string
+	name     string
+	email    string
+	parentID string
+	status   string
+}
+
+type fakeProduct struct {
+	id        string
+	name      string
+	artifacts []*fakeArtifact
+}
+
+type fakeArtifact struct {
+	id     string
+	name   string
+	active bool
+}
+
+type fakeProvisionedProduct struct {
+	id           string
+	name         string
+	productID    string
+	artifactID   string
+	pathID       string
+	status       string
+	lastRecordID string
+	stackID      string
+	accountID    string
+	parameters   map[string]string
+	tags         map[string]string
+}
+
+type fakeRecord struct {
+	id         string
+	ppID       string
+	ppName     string
+	productID  string
+	artifactID string
+	pathID     string
+	recordType string
+	status     string
+	errors     []string
+	outputs    map[string]string
+	polls      int
+}
+
+// newFakeAWS starts a new fake AWS backend which is stopped when the test finishes.
+func newFakeAWS(t testing.TB) *fakeAWS {
+	f := &fakeAWS{
+		pageSize:            20,
+		failRecords:         make(map[string]string),
+		errors:              make(map[string]string),
+		requests:            make(map[string]int),
+		hooks:               make(map[string]func()),
+		accessKeys:          make(map[string]string),
+		tags:                make(map[string]map[string]string),
+		products:            make(map[string]*fakeProduct),
+		alternateContacts:   make(map[string]*account.AlternateContact),
+		ous:                 make(map[string]*fakeOU),
+		accounts:            make(map[string]*fakeAccount),
+		provisionedProducts: make(map[string]*fakeProvisionedProduct),
+		records:             make(map[string]*fakeRecord),
+	}
+
+	f.products[fakeProductID] = &fakeProduct{
+		id:   fakeProductID,
+		name: "AWS Control Tower Account Factory",
+		artifacts: []*fakeArtifact{
+			{id: fakeArtifactIDOld, name: "v1"},
+			{id: fakeArtifactIDActive, name: "v2", active: true},
+		},
+	}
+
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+// providerConfig returns the provider configuration using the fake backend.
+func (f *fakeAWS) providerConfig() string {
+	return fmt.Sprintf(`
+provider "mcaf" {
+  aws {
+    access_key                  = "test"
+    secret_key                  = "test"
+    region                      = "eu-west-1"
+    skip_credentials_validation = true
+    skip_metadata_api_check     = true
+    skip_requesting_account_id  = true
+
+    endpoints {
+      account        = %[1]q
+      cloudformation = %[1]q
+      codebuild      = %[1]q
+      organizations  = %[1]q
+      servicecatalog = %[1]q
+    }
+  }
+}
+`, f.server.URL)
+}
+
+// client returns a provider client using the fake backend.
+func (f *fakeAWS) client(t testing.TB) *Client {
+	client, err := awsClient(map[string]interface{}{
+		"access_key":                  "test",
+		"secret_key":                  "test",
+		"profile":                     "",
+		"region":                      "eu-west-1",
+		"max_retries":                 0,
+		"shared_credentials_file":     "",
+		"skip_credentials_validation": true,
+		"skip_metadata_api_check":     true,
+		"skip_requesting_account_id":  true,
+		"token":                       "",
+		"endpoints": []interface{}{
+			map[string]interface{}{
+				"account":        f.server.URL,
+				"cloudformation": f.server.URL,
+				"codebuild":      f.server.URL,
+				"organizations":  f.server.URL,
+				"servicecatalog": f.server.URL,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Don't rate limit requests to the fake backend.
+	client.orgsLimiter = rate.NewLimiter(rate.Inf, 0)
+	client.scLimiter = rate.NewLimiter(rate.Inf, 0)
+
+	return &Client{
+		AWSClient:         client,
+		provisioningQueue: newProvisioningQueue(1),
+	}
+}
+
+func (f *fakeAWS) newID(prefix string) string {
+	f.nextID++
+	return fmt.Sprintf("%s%04d", prefix, f.nextID)
+}
+
+// addProduct adds a product with the provisioning artifacts and returns its ID.
+func (f *fakeAWS) addProduct(name string, artifacts ...*fakeArtifact) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := f.newID("prod-")
+	f.products[id] = &fakeProduct{id: id, name: name, artifacts: artifacts}
+
+	return id
+}
+
+// activateArtifact adds an active provisioning artifact to the product and deactivates
+// the others, like updating Control Tower does for the Account Factory.
+func (f *fakeAWS) activateArtifact(productID, artifactID, name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	product := f.products[productID]
+	for _, artifact := range product.artifacts {
+		artifact.active = false
+	}
+	product.artifacts = append(product.artifacts, &fakeArtifact{id: artifactID, name: name, active: true})
+}
+
+// addOU adds an organizational unit to the parent and returns its ID.
+func (f *fakeAWS) addOU(parentID, name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := f.newID("ou-fake-")
+	f.ous[id] = &fakeOU{id: id, name: name, parentID: parentID}
+
+	return id
+}
+
+// addAccount adds an unmanaged account to the parent and returns its ID.
+func (f *fakeAWS) addAccount(parentID, name, email string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	id := fmt.Sprintf("%012d", 100000000000+f.nextID)
+	f.accounts[id] = &fakeAccount{id: id, name: name, email: email, parentID: parentID}
+
+	return id
+}
+
+// setTags sets the tags of a resource.
+func (f *fakeAWS) setTags(id string, tags map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.tags[id] = tags
+}
+
+// setAccountParent moves the account to another parent, like someone using the console would.
+func (f *fakeAWS) setAccountParent(accountID, parentID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.accounts[accountID].parentID = parentID
+}
+
+// accountParent returns the parent ID of the account.
+func (f *fakeAWS) accountParent(accountID string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if account, ok := f.accounts[accountID]; ok {
+		return account.parentID
+	}
+
+	return ""
+}
+
+// requestCount returns the number of requests for the operation.
+func (f *fakeAWS) requestCount(operation string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.requests[operation]
+}
+
+// buildCount returns the number of started CodeBuild builds.
+func (f *fakeAWS) buildCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.builds)
+}
+
+// fakeError is an AWS error returned by the fake backend.
+type fakeError struct {
+	code    string
+	message string
+}
+
+func (f *fakeAWS) handle(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(f.latency)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// CloudFormation and STS use the query protocol, the Account Management API the REST JSON
+	// protocol with the operation in the path, all other services the JSON protocol.
+	target := r.Header.Get("X-Amz-Target")
+	if target == "" && strings.HasSuffix(r.URL.Path, "AlternateContact") {
+		target = "Account." + strings.ToUpper(r.URL.Path[1:2]) + r.URL.Path[2:]
+	}
+	if target == "" {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.handleQuery(w, r)
+		return
+	}
+
+	operation := target[strings.LastIndex(target, ".")+1:]
+	f.requests[operation]++
+	f.accessKeys[operation] = fakeAccessKeyID(r)
+
+	if hook, ok := f.hooks[operation]; ok {
+		hook()
+	}
+
+	var output interface{}
+	var err *fakeError
+
+	if code, ok := f.errors[operation]; ok {
+		err = &fakeError{code: code, message: fmt.Sprintf("injected %s error", operation)}
+	} else {
+		output, err = f.dispatch(operation, r)
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"__type": err.code, "message": err.message})
+		return
+	}
+
+	body, buildErr := jsonutil.BuildJSON(output)
+	if buildErr != nil {
+		http.Error(w, buildErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(body)
+}
+
+func (f *fakeAWS) dispatch(operation string, r *http.Request) (interface{}, *fakeError) {
+	decode := func(v interface{}) {
+		if err := jsonutil.UnmarshalJSON(v, r.Body); err != nil {
+			panic(fmt.Sprintf("decoding %s input: %s", operation, err))
+		}
+	}
+
+	switch operation {
+	// Organizations
+	case "CreateOrganizationalUnit":
+		input := &organizations.CreateOrganizationalUnitInput{}
+		decode(input)
+		return f.createOrganizationalUnit(input)
+	case "UpdateOrganizationalUnit":
+		input := &organizations.UpdateOrganizationalUnitInput{}
+		decode(input)
+		return f.updateOrganizationalUnit(input)
+	case "DeleteOrganizationalUnit":
+		input := &organizations.DeleteOrganizationalUnitInput{}
+		decode(input)
+		return f.deleteOrganizationalUnit(input)
+	case "MoveAccount":
+		input := &organizations.MoveAccountInput{}
+		decode(input)
+		return f.moveAccount(input)
+	case "CloseAccount":
+		input := &organizations.CloseAccountInput{}
+		decode(input)
+		return f.closeAccount(input)
+	case "DescribeAccount":
+		input := &organizations.DescribeAccountInput{}
+		decode(input)
+		return f.describeAccount(input)
+	case "TagResource":
+		input := &organizations.TagResourceInput{}
+		decode(input)
+		return f.tagResource(input), nil
+	case "UntagResource":
+		input := &organizations.UntagResourceInput{}
+		decode(input)
+		return f.untagResource(input), nil
+	case "ListRoots":
+		return f.listRoots(), nil
+	case "ListOrganizationalUnitsForParent":
+		input := &organizations.ListOrganizationalUnitsForParentInput{}
+		decode(input)
+		if err := f.injectedError(operation, aws.StringValue(input.ParentId)); err != nil {
+			return nil, err
+		}
+		return f.listOrganizationalUnitsForParent(input)
+	case "ListAccounts":
+		input := &organizations.ListAccountsInput{}
+		decode(input)
+		return f.listAccounts(input), nil
+	case "ListAccountsForParent":
+		input := &organizations.ListAccountsForParentInput{}
+		decode(input)
+		if err := f.injectedError(operation, aws.StringValue(input.ParentId)); err != nil {
+			return nil, err
+		}
+		return f.listAccountsForParent(input)
+	case "ListTagsForResource":
+		input := &organizations.ListTagsForResourceInput{}
+		decode(input)
+		return f.listTagsForResource(input), nil
+	case "ListParents":
+		input := &organizations.ListParentsInput{}
+		decode(input)
+		return f.listParents(input)
+	case "DescribeOrganizationalUnit":
+		input := &organizations.DescribeOrganizationalUnitInput{}
+		decode(input)
+		return f.describeOrganizationalUnit(input)
+
+	// Account Management
+	case "GetAlternateContact":
+		input := &account.GetAlternateContactInput{}
+		decode(input)
+		return f.getAlternateContact(input)
+	case "PutAlternateContact":
+		input := &account.PutAlternateContactInput{}
+		decode(input)
+		return f.putAlternateContact(input)
+	case "DeleteAlternateContact":
+		input := &account.DeleteAlternateContactInput{}
+		decode(input)
+		return f.deleteAlternateContact(input)
+
+	// Service Catalog
+	case "SearchProducts":
+		input := &servicecatalog.SearchProductsInput{}
+		decode(input)
+		return f.searchProducts(input), nil
+	case "DescribeProduct":
+		input := &servicecatalog.DescribeProductInput{}
+		decode(input)
+		return f.describeProduct(input)
+	case "ListProvisioningArtifacts":
+		input := &servicecatalog.ListProvisioningArtifactsInput{}
+		decode(input)
+		return f.listProvisioningArtifacts(input)
+	case "ProvisionProduct":
+		input := &servicecatalog.ProvisionProductInput{}
+		decode(input)
+		return f.provisionProduct(input)
+	case "UpdateProvisionedProduct":
+		input := &servicecatalog.UpdateProvisionedProductInput{}
+		decode(input)
+		return f.updateProvisionedProduct(input)
+	case "TerminateProvisionedProduct":
+		input := &servicecatalog.TerminateProvisionedProductInput{}
+		decode(input)
+		return f.terminateProvisionedProduct(input)
+	case "DescribeProvisionedProduct":
+		input := &servicecatalog.DescribeProvisionedProductInput{}
+		decode(input)
+		return f.describeProvisionedProduct(input)
+	case "DescribeRecord":
+		input := &servicecatalog.DescribeRecordInput{}
+		decode(input)
+		return f.describeRecord(input)
+	case "SearchProvisionedProducts":
+		input := &servicecatalog.SearchProvisionedProductsInput{}
+		decode(input)
+		return f.searchProvisionedProducts(input), nil
+	case "GetProvisionedProductOutputs":
+		input := &servicecatalog.GetProvisionedProductOutputsInput{}
+		decode(input)
+		if err := f.injectedError(operation, aws.StringValue(input.ProvisionedProductId)); err != nil {
+			return nil, err
+		}
+		return f.getProvisionedProductOutputs(input)
+
+	// CodeBuild
+	case "StartBuild":
+		input := &codebuild.StartBuildInput{}
+		decode(input)
+		f.builds = append(f.builds, input)
+		return &codebuild.StartBuildOutput{
+			Build: &codebuild.Build{
+				Id:            aws.String(fmt.Sprintf("%s:%d", aws.StringValue(input.ProjectName), len(f.builds))),
+				ProjectName:   input.ProjectName,
+				SourceVersion: input.SourceVersion,
+			},
+		}, nil
+	}
+
+	return nil, &fakeError{code: "UnknownOperationException", message: "unsupported operation " + operation}
+}
+
+// injectedError returns the error injected for the operation on the resource, if any.
+func (f *fakeAWS) injectedError(operation, id string) *fakeError {
+	if code, ok := f.errors[operation+"/"+id]; ok {
+		return &fakeError{code: code, message: fmt.Sprintf("injected %s error for %s", operation, id)}
+	}
+	return nil
+}
+
+// paginate returns the page of n items starting at the token and the next token.
+func (f *fakeAWS) paginate(n int, token *string) (int, int, *string) {
+	start, _ := strconv.Atoi(aws.StringValue(token))
+	end := min(start+f.pageSize, n)
+
+	if end < n {
+		return start, end, aws.String(strconv.Itoa(end))
+	}
+
+	return start, end, nil
+}
+
+func (f *fakeAWS) listRoots() *organizations.ListRootsOutput {
+	return &organizations.ListRootsOutput{
+		Roots: []*organizations.Root{{
+			Arn:  aws.String("arn:aws:organizations::000000000000:root/o-fake/" + fakeRootID),
+			Id:   aws.String(fakeRootID),
+			Name: aws.String("Root"),
+		}},
+	}
+}
+
+func (f *fakeAWS) organizationalUnit(ou *fakeOU) *organizations.OrganizationalUnit {
+	return &organizations.OrganizationalUnit{
+		Arn:  aws.String("arn:aws:organizations::000000000000:ou/o-fake/" + ou.id),
+		Id:   aws.String(ou.id),
+		Name: aws.String(ou.name),
+	}
+}
+
+func (f *fakeAWS) listOrganizationalUnitsForParent(input *organizations.ListOrganizationalUnitsForParentInput) (interface{}, *fakeError) {
+	parentID := aws.StringValue(input.ParentId)
+	if _, ok := f.ous[parentID]; !ok && parentID != fakeRootID {
+		return nil, &fakeError{code: organizations.ErrCodeParentNotFoundException, message: "parent not found: " + parentID}
+	}
+
+	var children []*fakeOU
+	for _, ou := range f.ous {
+		if ou.parentID == parentID {
+			children = append(children, ou)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].id < children[j].id })
+
+	start, end, next := f.paginate(len(children), input.NextToken)
+	if maxResults := int(aws.Int64Value(input.MaxResults)); maxResults > 0 && end-start > maxResults {
+		end = start + maxResults
+		next = aws.String(strconv.Itoa(end))
+	}
+
+	output := &organizations.ListOrganizationalUnitsForParentOutput{NextToken: next}
+	for _, ou := range children[start:end] {
+		output.OrganizationalUnits = append(output.OrganizationalUnits, f.organizationalUnit(ou))
+	}
+
+	return output, nil
+}
+
+func (f *fakeAWS) account(account *fakeAccount) *organizations.Account {
+	status := account.status
+	if status == "" {
+		status = organizations.AccountStatusActive
+	}
+
+	return &organizations.Account{
+		Arn:             aws.String("arn:aws:organizations::000000000000:account/o-fake/" + account.id),
+		Email:           aws.String(account.email),
+		Id:              aws.String(account.id),
+		JoinedMethod:    aws.String(organizations.AccountJoinedMethodCreated),
+		JoinedTimestamp: aws.Time(fakeJoinedTimestamp),
+		Name:            aws.String(account.name),
+		Status:          aws.String(status),
+	}
+}
+
+func (f *fakeAWS) listAccounts(input *organizations.ListAccountsInput) interface{} {
+	var accounts []*fakeAccount
+	for _, account := range f.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].id < accounts[j].id })
+
+	start, end, next := f.paginate(len(accounts), input.NextToken)
+
+	output := &organizations.ListAccountsOutput{NextToken: next}
+	for _, account := range accounts[start:end] {
+		output.Accounts = append(output.Accounts, f.account(account))
+	}
+
+	return output
+}
+
+func (f *fakeAWS) listAccountsForParent(input *organizations.ListAccountsForParentInput) (interface{}, *fakeError) {
+	parentID := aws.StringValue(input.ParentId)
+	if _, ok := f.ous[parentID]; !ok && parentID != fakeRootID {
+		return nil, &fakeError{code: organizations.ErrCodeParentNotFoundException, message: "parent not found: " + parentID}
+	}
+
+	var accounts []*fakeAccount
+	for _, account := range f.accounts {
+		if account.parentID == parentID {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].id < accounts[j].id })
+
+	start, end, next := f.paginate(len(accounts), input.NextToken)
+
+	output := &organizations.ListAccountsForParentOutput{NextToken: next}
+	for _, account := range accounts[start:end] {
+		output.Accounts = append(output.Accounts, f.account(account))
+	}
+
+	return output, nil
+}
+
+func (f *fakeAWS) createOrganizationalUnit(input *organizations.CreateOrganizationalUnitInput) (interface{}, *fakeError) {
+	parentID := aws.StringValue(input.ParentId)
+	if _, ok := f.ous[parentID]; !ok && parentID != fakeRootID {
+		return nil, &fakeError{code: organizations.ErrCodeParentNotFoundException, message: "parent not found: " + parentID}
+	}
+
+	for _, ou := range f.ous {
+		if ou.parentID == parentID && ou.name == aws.StringValue(input.Name) {
+			return nil, &fakeError{code: organizations.ErrCodeDuplicateOrganizationalUnitException, message: "duplicate organizational unit"}
+		}
+	}
+
+	id := f.newID("ou-fake-")
+	f.ous[id] = &fakeOU{id: id, name: aws.StringValue(input.Name), parentID: parentID}
+	f.tagResource(&organizations.TagResourceInput{ResourceId: aws.String(id), Tags: input.Tags})
+
+	return &organizations.CreateOrganizationalUnitOutput{OrganizationalUnit: f.organizationalUnit(f.ous[id])}, nil
+}
+
+func (f *fakeAWS) updateOrganizationalUnit(input *organizations.UpdateOrganizationalUnitInput) (interface{}, *fakeError) {
+	ou, ok := f.ous[aws.StringValue(input.OrganizationalUnitId)]
+	if !ok {
+		return nil, &fakeError{code: organizations.ErrCodeOrganizationalUnitNotFoundException, message: "organizational unit not found"}
+	}
+
+	ou.name = aws.StringValue(input.Name)
+
+	return &organizations.UpdateOrganizationalUnitOutput{OrganizationalUnit: f.organizationalUnit(ou)}, nil
+}
+
+func (f *fakeAWS) deleteOrganizationalUnit(input *organizations.DeleteOrganizationalUnitInput) (interface{}, *fakeError) {
+	id := aws.StringValue(input.OrganizationalUnitId)
+	if _, ok := f.ous[id]; !ok {
+		return nil, &fakeError{code: organizations.ErrCodeOrganizationalUnitNotFoundException, message: "organizational unit not found"}
+	}
+
+	for _, ou := range f.ous {
+		if ou.parentID == id {
+			return nil, &fakeError{code: organizations.ErrCodeOrganizationalUnitNotEmptyException, message: "organizational unit contains organizational units"}
+		}
+	}
+	for _, account := range f.accounts {
+		if account.parentID == id {
+			return nil, &fakeError{code: organizations.ErrCodeOrganizationalUnitNotEmptyException, message: "organizational unit contains accounts"}
+		}
+	}
+
+	delete(f.ous, id)
+	delete(f.tags, id)
+
+	return &organizations.DeleteOrganizationalUnitOutput{}, nil
+}
+
+func (f *fakeAWS) moveAccount(input *organizations.MoveAccountInput) (interface{}, *fakeError) {
+	account, ok := f.accounts[aws.StringValue(input.AccountId)]
+	if !ok {
+		return nil, &fakeError{code: organizations.ErrCodeAccountNotFoundException, message: "account not found"}
+	}
+	if account.parentID != aws.StringValue(input.SourceParentId) {
+		return nil, &fakeError{code: organizations.ErrCodeSourceParentNotFoundException, message: "account is not in the source parent"}
+	}
+
+	destinationID := aws.StringValue(input.DestinationParentId)
+	if _, ok := f.ous[destinationID]; !ok && destinationID != fakeRootID {
+		return nil, &fakeError{code: organizations.ErrCodeDestinationParentNotFoundException, message: "destination parent not found"}
+	}
+
+	account.parentID = destinationID
+
+	return &organizations.MoveAccountOutput{}, nil
+}
+
+func (f *fakeAWS) closeAccount(input *organizations.CloseAccountInput) (interface{}, *fakeError) {
+	account, ok := f.accounts[aws.StringValue(input.AccountId)]
+	if !ok {
+		return nil, &fakeError{code: organizations.ErrCodeAccountNotFoundException, message: "account not found"}
+	}
+	if account.status == organizations.AccountStatusPendingClosure || account.status == organizations.AccountStatusSuspended {
+		return nil, &fakeError{code: organizations.ErrCodeAccountAlreadyClosedException, message: "account is already closed"}
+	}
+
+	account.status = organizations.AccountStatusPendingClosure
+
+	return &organizations.CloseAccountOutput{}, nil
+}
+
+// describeAccount reports a closing account as pending closure once, and as
+// suspended on every following call.
+func (f *fakeAWS) describeAccount(input *organizations.DescribeAccountInput) (interface{}, *fakeError) {
+	account, ok := f.accounts[aws.StringValue(input.AccountId)]
+	if !ok {
+		return nil, &fakeError{code: organizations.ErrCodeAccountNotFoundException, message: "account not found"}
+	}
+
+	output := &organizations.DescribeAccountOutput{Account: f.account(account)}
+	if account.status == organizations.AccountStatusPendingClosure {
+		account.status = organizations.AccountStatusSuspended
+	}
+
+	return output, nil
+}
+
+func (f *fakeAWS) tagResource(input *organizations.TagResourceInput) interface{} {
+	id := aws.StringValue(input.ResourceId)
+	if f.tags[id] == nil {
+		f.tags[id] = make(map[string]string)
+	}
+	for _, tag := range input.Tags {
+		f.tags[id][aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return &organizations.TagResourceOutput{}
+}
+
+func (f *fakeAWS) untagResource(input *organizations.UntagResourceInput) interface{} {
+	for _, key := range input.TagKeys {
+		delete(f.tags[aws.StringValue(input.ResourceId)], aws.StringValue(key))
+	}
+
+	return &organizations.UntagResourceOutput{}
+}
+
+func (f *fakeAWS) listTagsForResource(input *organizations.ListTagsForResourceInput) interface{} {
+	tags := f.tags[aws.StringValue(input.ResourceId)]
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	output := &organizations.ListTagsForResourceOutput{}
+	for _, k := range keys {
+		output.Tags = append(output.Tags, &organizations.Tag{Key: aws.String(k), Value: aws.String(tags[k])})
+	}
+
+	return output
+}
+
+func (f *fakeAWS) listParents(input *organizations.ListParentsInput) (interface{}, *fakeError) {
+	childID := aws.StringValue(input.ChildId)
+
+	var parentID string
+	if account, ok := f.accounts[childID]; ok {
+		parentID = account.parentID
+	} else if ou, ok := f.ous[childID]; ok {
+		parentID = ou.parentID
+	} else {
+		return nil, &fakeError{code: organizations.ErrCodeChildNotFoundException, message: "child not found: " + childID}
+	}
+
+	parentType := organizations.ParentTypeOrganizationalUnit
+	if parentID == fakeRootID {
+		parentType = organizations.ParentTypeRoot
+	}
+
+	return &organizations.ListParentsOutput{
+		Parents: []*organizations.Parent{{Id: aws.String(parentID), Type: aws.String(parentType)}},
+	}, nil
+}
+
+func (f *fakeAWS) describeOrganizationalUnit(input *organizations.DescribeOrganizationalUnitInput) (interface{}, *fakeError) {
+	ou, ok := f.ous[aws.StringValue(input.OrganizationalUnitId)]
+	if !ok {
+		return nil, &fakeError{code: organizations.ErrCodeOrganizationalUnitNotFoundException, message: "organizational unit not found"}
+	}
+
+	return &organizations.DescribeOrganizationalUnitOutput{OrganizationalUnit: f.organizationalUnit(ou)}, nil
+}
+
+// alternateContactKey returns the key of the alternate contact, the account ID defaults
+// to the management account.
+func (f *fakeAWS) alternateContactKey(accountID *string, contactType *string) (string, *fakeError) {
+	id := aws.StringValue(accountID)
+	if id == "" {
+		id = fakeManagementAccountID
+	} else if _, ok := f.accounts[id]; !ok {
+		return "", &fakeError{code: account.ErrCodeAccessDeniedException, message: "account is not a member of the organization"}
+	}
+
+	return id + "/" + aws.StringValue(contactType), nil
+}
+
+func (f *fakeAWS) getAlternateContact(input *account.GetAlternateContactInput) (interface{}, *fakeError) {
+	key, err := f.alternateContactKey(input.AccountId, input.AlternateContactType)
+	if err != nil {
+		return nil, err
+	}
+
+	contact, ok := f.alternateContacts[key]
+	if !ok {
+		return nil, &fakeError{code: account.ErrCodeResourceNotFoundException, message: "alternate contact not found"}
+	}
+
+	return &account.GetAlternateContactOutput{AlternateContact: contact}, nil
+}
+
+func (f *fakeAWS) putAlternateContact(input *account.PutAlternateContactInput) (interface{}, *fakeError) {
+	key, err := f.alternateContactKey(input.AccountId, input.AlternateContactType)
+	if err != nil {
+		return nil, err
+	}
+
+	f.alternateContacts[key] = &account.AlternateContact{
+		AlternateContactType: input.AlternateContactType,
+		EmailAddress:         input.EmailAddress,
+		Name:                 input.Name,
+		PhoneNumber:          input.PhoneNumber,
+		Title:                input.Title,
+	}
+
+	return &account.PutAlternateContactOutput{}, nil
+}
+
+func (f *fakeAWS) deleteAlternateContact(input *account.DeleteAlternateContactInput) (interface{}, *fakeError) {
+	key, err := f.alternateContactKey(input.AccountId, input.AlternateContactType)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := f.alternateContacts[key]; !ok {
+		return nil, &fakeError{code: account.ErrCodeResourceNotFoundException, message: "alternate contact not found"}
+	}
+	delete(f.alternateContacts, key)
+
+	return &account.DeleteAlternateContactOutput{}, nil
+}
+
+func (f *fakeAWS) productViewSummary(product *fakeProduct) *servicecatalog.ProductViewSummary {
+	return &servicecatalog.ProductViewSummary{
+		Id:        aws.String("prodview-" + strings.TrimPrefix(product.id, "prod-")),
+		Name:      aws.String(product.name),
+		ProductId: aws.String(product.id),
+	}
+}
+
+func (f *fakeAWS) searchProducts(input *servicecatalog.SearchProductsInput) *servicecatalog.SearchProductsOutput {
+	var products []*fakeProduct
+	for _, product := range f.products {
+		match := true
+		for _, text := range aws.StringValueSlice(input.Filters["FullTextSearch"]) {
+			if !strings.Contains(strings.ToLower(product.name), strings.ToLower(text)) {
+				match = false
+			}
+		}
+		if match {
+			products = append(products, product)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].id < products[j].id })
+
+	output := &servicecatalog.SearchProductsOutput{}
+	for _, product := range products {
+		output.ProductViewSummaries = append(output.ProductViewSummaries, f.productViewSummary(product))
+	}
+
+	return output
+}
+
+func (f *fakeAWS) describeProduct(input *servicecatalog.DescribeProductInput) (interface{}, *fakeError) {
+	for _, product := range f.products {
+		if product.id == aws.StringValue(input.Id) || product.name == aws.StringValue(input.Name) {
+			return &servicecatalog.DescribeProductOutput{ProductViewSummary: f.productViewSummary(product)}, nil
+		}
+	}
+
+	return nil, &fakeError{code: servicecatalog.ErrCodeResourceNotFoundException, message: "product not found"}
+}
+
+func (f *fakeAWS) listProvisioningArtifacts(input *servicecatalog.ListProvisioningArtifactsInput) (interface{}, *fakeError) {
+	product, ok := f.products[aws.StringValue(input.ProductId)]
+	if !ok {
+		return nil, &fakeError{code: servicecatalog.ErrCodeResourceNotFoundException, message: "product not found"}
+	}
+
+	output := &servicecatalog.ListProvisioningArtifactsOutput{}
+	for _, artifact := range product.artifacts {
+		output.ProvisioningArtifactDetails = append(output.ProvisioningArtifactDetails, &servicecatalog.ProvisioningArtifactDetail{
+			Active: aws.Bool(artifact.active),
+			Id:     aws.String(artifact.id),
+			Name:   aws.String(artifact.name),
+		})
+	}
+
+	return output, nil
+}
+
+// productArtifact returns whether the product has the provisioning artifact.
+func (f *fakeAWS) productArtifact(productID, artifactID string) bool {
+	product, ok := f.products[productID]
+	if !ok {
+		return false
+	}
+
+	for _, artifact := range product.artifacts {
+		if artifact.id == artifactID {
+			return true
+		}
+	}
+
+	return false
+}
+
+var fakeManagedOuRegexp = regexp.MustCompile(`\((.+)\)$`)
+
+// provision applies the provisioning parameters and returns the record of the run.
+func (f *fakeAWS) provision(pp *fakeProvisionedProduct, recordType string) *fakeRecord {
+	record := &fakeRecord{
+		id:         f.newID("rec-"),
+		ppID:       pp.id,
+		ppName:     pp.name,
+		productID:  pp.productID,
+		artifactID: pp.artifactID,
+		pathID:     pp.pathID,
+		recordType: recordType,
+		status:     servicecatalog.RecordStatusInProgress,
+	}
+	f.records[record.id] = record
+	pp.lastRecordID = record.id
+
+	name := pp.parameters["AccountName"]
+	if description, ok := f.failRecords[name]; ok {
+		record.errors = []string{description}
+		pp.status = servicecatalog.ProvisionedProductStatusError
+		return record
+	}
+
+	parentID := fakeRootID
+	if match := fakeManagedOuRegexp.FindStringSubmatch(pp.parameters["ManagedOrganizationalUnit"]); match != nil {
+		parentID = match[1]
+	}
+
+	if pp.accountID == "" {
+		f.nextID++
+		pp.accountID = fmt.Sprintf("%012d", 100000000000+f.nextID)
+	}
+
+	f.accounts[pp.accountID] = &fakeAccount{
+		id:       pp.accountID,
+		name:     name,
+		email:    pp.parameters["AccountEmail"],
+		parentID: parentID,
+	}
+
+	pp.status = servicecatalog.ProvisionedProductStatusAvailable
+	record.outputs = map[string]string{
+		"AccountEmail": pp.parameters["AccountEmail"],
+		"AccountId":    pp.accountID,
+		"AccountName":  name,
+		"SSOUserEmail": pp.parameters["SSOUserEmail"],
+	}
+
+	return record
+}
+
+func (f *fakeAWS) recordDetail(record *fakeRecord) *servicecatalog.RecordDetail {
+	detail := &servicecatalog.RecordDetail{
+		PathId:                 aws.String(record.pathID),
+		ProductId:              aws.String(record.productID),
+		ProvisionedProductId:   aws.String(record.ppID),
+		ProvisionedProductName: aws.String(record.ppName),
+		ProvisioningArtifactId: aws.String(record.artifactID),
+		RecordId:               aws.String(record.id),
+		RecordType:             aws.String(record.recordType),
+		Status:                 aws.String(record.status),
+	}
+
+	for _, description := range record.errors {
+		detail.RecordErrors = append(detail.RecordErrors, &servicecatalog.RecordError{
+			Code:        aws.String("ProvisioningFailed"),
+			Description: aws.String(description),
+		})
+	}
+
+	return detail
+}
+
+func provisioningParameters(params []*servicecatalog.ProvisioningParameter) map[string]string {
+	parameters := make(map[string]string)
+	for _, param := range params {
+		parameters[aws.StringValue(param.Key)] = aws.StringValue(param.Value)
+	}
+	return parameters
+}
+
+func (f *fakeAWS) provisionProduct(input *servicecatalog.ProvisionProductInput) (interface{}, *fakeError) {
+	if !f.productArtifact(aws.StringValue(input.ProductId), aws.StringValue(input.ProvisioningArtifactId)) {
+		return nil, &fakeError{code: servicecatalog.ErrCodeResourceNotFoundException, message: "product or provisioning artifact not found"}
+	}
+
+	for _, pp := range f.provisionedProducts {
+		if pp.name == aws.StringValue(input.ProvisionedProductName) {
+			return nil, &fakeError{code: servicecatalog.ErrCodeDuplicateResourceException, message: "provisioned product already exists"}
+		}
+	}
+
+	pp := &fakeProvisionedProduct{
+		id:         f.newID("pp-"),
+		name:       aws.StringValue(input.ProvisionedProductName),
+		productID:  aws.StringValue(input.ProductId),
+		artifactID: aws.StringValue(input.ProvisioningArtifactId),
+		pathID:     aws.StringValue(input.PathId),
+		parameters: provisioningParameters(input.ProvisioningParameters),
+		tags:       make(map[string]string),
+	}
+	for _, tag := range input.Tags {
+		pp.tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	pp.stackID = "arn:aws:cloudformation:eu-west-1:000000000000:stack/SC-000000000000-" + pp.id + "/fake"
+	f.provisionedProducts[pp.id] = pp
+
+	record := f.provision(pp, "PROVISION_PRODUCT")
+
+	return &servicecatalog.ProvisionProductOutput{RecordDetail: f.recordDetail(record)}, nil
+}
+
+func (f *fakeAWS) updateProvisionedProduct(input *servicecatalog.UpdateProvisionedProductInput) (interface{}, *fakeError) {
+	pp, ok := f.provisionedProducts[aws.StringValue(input.ProvisionedProductId)]
+	if !ok {
+		return nil, &fakeError{code: servicecatalog.ErrCodeResourceNotFoundException, message: "provisioned product not found"}
+	}
+
+	productID, artifactID := pp.productID, pp.artifactID
+	if input.ProductId != nil {
+		productID = aws.StringValue(input.ProductId)
+	}
+	if input.ProvisioningArtifactId != nil {
+		artifactID = aws.StringValue(input.ProvisioningArtifactId)
+	}
+	if !f.productArtifact(productID, artifactID) {
+		return nil, &fakeError{code: servicecatalog.ErrCodeResourceNotFoundException, message: "product or provisioning artifact not found"}
+	}
+
+	for _, param := range input.ProvisioningParameters {
+		pp.parameters[aws.StringValue(param.Key)] = aws.StringValue(param.Value)
+	}
+	pp.productID, pp.artifactID = productID, artifactID
+	if input.PathId != nil {
+		pp.pathID = aws.StringValue(input.PathId)
+	}
+
+	record := f.provision(pp, "UPDATE_PROVISIONED_PRODUCT")
+
+	return &servicecatalog.UpdateProvisionedProductOutput{RecordDetail: f.recordDetail(record)}, nil
+}
+
+func (f *fakeAWS) terminateProvisionedProduct(input *servicecatalog.TerminateProvisionedProductInput) (interface{}, *fakeError) {
+	pp, ok := f.provisionedProducts[aws.StringValue(input.ProvisionedProductId)]
+	if !ok {
+		return nil, &fakeError{code: servicecatalog.ErrCodeResourceNotFoundException, message: "provisioned product not found"}
+	}
+
+	record := &fakeRecord{
+		id:         f.newID("rec-"),
+		ppID:       pp.id,
+		ppName:     pp.name,
+		productID:  pp.productID,
+		artifactID: pp.artifactID,
+		pathID:     pp.pathID,
+		recordType: "TERMINATE_PROVISIONED_PRODUCT",
+		status:     servicecatalog.RecordStatusInProgress,
+	}
+	f.records[record.id] = record
+
+	// Terminating the provisioned product unmanages the account and moves it to the root.
+	if account, ok := f.accounts[pp.accountID]; ok {
+		account.parentID = fakeRootID
+	}
+	delete(f.provisionedProducts, pp.id)
+
+	return &servicecatalog.TerminateProvisionedProductOutput{RecordDetail: f.recordDetail(record)}, nil
+}
+
+func (f *fakeAWS) describeProvisionedProduct(input *servicecatalog.DescribeProvisionedProductInput) (interface{}, *fakeError) {
+	pp, ok := f.provisionedProducts[aws.StringValue(input.Id)]
+	if !ok {
+		return nil, &fakeError{code: servicecatalog.ErrCodeResourceNotFoundException, message: "provisioned product not found"}
+	}
+
+	return &servicecatalog.DescribeProvisionedProductOutput{
+		ProvisionedProductDetail: &servicecatalog.ProvisionedProductDetail{
+			Id:                     aws.String(pp.id),
+			LastRecordId:           aws.String(pp.lastRecordID),
+			Name:                   aws.String(pp.name),
+			ProductId:              aws.String(pp.productID),
+			ProvisioningArtifactId: aws.String(pp.artifactID),
+			Status:                 aws.String(pp.status),
+		},
+	}, nil
+}
+
+// describeRecord reports a record as in progress on the first call and as finished
+// on every following call, so waiting for the provisioning is exercised.
+func (f *fakeAWS) describeRecord(input *servicecatalog.DescribeRecordInput) (interface{}, *fakeError) {
+	record, ok := f.records[aws.StringValue(input.Id)]
+	if !ok {
+		return nil, &fakeError{code: servicecatalog.ErrCodeResourceNotFoundException, message: "record not found"}
+	}
+
+	if record.status == servicecatalog.RecordStatusInProgress {
+		if record.polls > 0 {
+			record.status = servicecatalog.RecordStatusSucceeded
+			if len(record.errors) > 0 {
+				record.status = servicecatalog.RecordStatusFailed
+			}
+		}
+		record.polls++
+	}
+
+	output := &servicecatalog.DescribeRecordOutput{RecordDetail: f.recordDetail(record)}
+	for key, value := range record.outputs {
+		output.RecordOutputs = append(output.RecordOutputs, &servicecatalog.RecordOutput{
+			OutputKey:   aws.String(key),
+			OutputValue: aws.String(value),
+		})
+	}
+
+	return output, nil
+}
+
+func (f *fakeAWS) searchProvisionedProducts(input *servicecatalog.SearchProvisionedProductsInput) interface{} {
+	var pps []*fakeProvisionedProduct
+	for _, pp := range f.provisionedProducts {
+		if f.matchesSearchQuery(pp, input.Filters["SearchQuery"]) {
+			pps = append(pps, pp)
+		}
+	}
+	sort.Slice(pps, func(i, j int) bool { return pps[i].id < pps[j].id })
+
+	start, end, next := f.paginate(len(pps), input.PageToken)
+
+	output := &servicecatalog.SearchProvisionedProductsOutput{NextPageToken: next}
+	for _, pp := range pps[start:end] {
+		output.ProvisionedProducts = append(output.ProvisionedProducts, &servicecatalog.ProvisionedProductAttribute{
+			Id:                     aws.String(pp.id),
+			LastRecordId:           aws.String(pp.lastRecordID),
+			Name:                   aws.String(pp.name),
+			PhysicalId:             aws.String(pp.stackID),
+			ProductId:              aws.String(pp.productID),
+			ProvisioningArtifactId: aws.String(pp.artifactID),
+			Status:                 aws.String(pp.status),
+		})
+	}
+
+	return output
+}
+
+// matchesSearchQuery returns whether the provisioned product matches all queries. Queries
+// without a field are keywords, matched against the attributes of the provisioned product
+// like Service Catalog does, so never against its outputs.
+func (f *fakeAWS) matchesSearchQuery(pp *fakeProvisionedProduct, queries []*string) bool {
+	for _, query := range aws.StringValueSlice(queries) {
+		field, value, ok := strings.Cut(query, ":")
+		if !ok {
+			if !f.matchesKeyword(pp, query) {
+				return false
+			}
+			continue
+		}
+
+		switch field {
+		case "id":
+			if pp.id != value {
+				return false
+			}
+		case "productId":
+			if pp.productID != value {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (f *fakeAWS) matchesKeyword(pp *fakeProvisionedProduct, keyword string) bool {
+	for _, value := range []string{pp.id, pp.name, pp.stackID, pp.productID} {
+		if strings.EqualFold(value, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *fakeAWS) getProvisionedProductOutputs(input *servicecatalog.GetProvisionedProductOutputsInput) (interface{}, *fakeError) {
+	pp, ok := f.provisionedProducts[aws.StringValue(input.ProvisionedProductId)]
+	if !ok {
+		return nil, &fakeError{code: servicecatalog.ErrCodeResourceNotFoundException, message: "provisioned product not found"}
+	}
+
+	output := &servicecatalog.GetProvisionedProductOutputsOutput{}
+	for key, value := range f.records[pp.lastRecordID].outputs {
+		output.Outputs = append(output.Outputs, &servicecatalog.RecordOutput{
+			OutputKey:   aws.String(key),
+			OutputValue: aws.String(value),
+		})
+	}
+
+	return output, nil
+}
+
+type fakeStackParameter struct {
+	ParameterKey   string
+	ParameterValue string
+}
+
+type fakeStack struct {
+	StackId      string
+	StackName    string
+	StackStatus  string
+	CreationTime string
+	Parameters   []fakeStackParameter `xml:"Parameters>member"`
+}
+
+type fakeDescribeStacksResponse struct {
+	XMLName xml.Name    `xml:"DescribeStacksResponse"`
+	Stacks  []fakeStack `xml:"DescribeStacksResult>Stacks>member"`
+}
+
+type fakeErrorResponse struct {
+	XMLName xml.Name `xml:"ErrorResponse"`
+	Code    string   `xml:"Error>Code"`
+	Message string   `xml:"Error>Message"`
+}
+
+type fakeCredentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      string
+}
+
+type fakeAssumeRoleResponse struct {
+	XMLName     xml.Name        `xml:"AssumeRoleResponse"`
+	Credentials fakeCredentials `xml:"AssumeRoleResult>Credentials"`
+}
+
+type fakeAssumeRoleWithWebIdentityResponse struct {
+	XMLName     xml.Name        `xml:"AssumeRoleWithWebIdentityResponse"`
+	Credentials fakeCredentials `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+}
+
+type fakeGetCallerIdentityResponse struct {
+	XMLName xml.Name `xml:"GetCallerIdentityResponse"`
+	Account string   `xml:"GetCallerIdentityResult>Account"`
+	Arn     string   `xml:"GetCallerIdentityResult>Arn"`
+	UserId  string   `xml:"GetCallerIdentityResult>UserId"`
+}
+
+// fakeAccessKeyIDRegexp matches the access key ID in the signature of a request.
+var fakeAccessKeyIDRegexp = regexp.MustCompile(`Credential=([^/]+)/`)
+
+// fakeAccessKeyID returns the access key ID the request was signed with.
+func fakeAccessKeyID(r *http.Request) string {
+	if m := fakeAccessKeyIDRegexp.FindStringSubmatch(r.Header.Get("Authorization")); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
+
+func (f *fakeAWS) handleQuery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/xml")
+
+	form := r.Form
+	action := strings.Join(form["Action"], "")
+	f.requests[action]++
+	f.accessKeys[action] = fakeAccessKeyID(r)
+
+	switch action {
+	case "DescribeStacks":
+		f.describeStacks(w, form)
+	case "AssumeRoleWithWebIdentity":
+		// The credentials are issued already expired, so every request refreshes them.
+		xml.NewEncoder(w).Encode(fakeAssumeRoleWithWebIdentityResponse{Credentials: f.credentials("webidentity")})
+	case "AssumeRole":
+		xml.NewEncoder(w).Encode(fakeAssumeRoleResponse{Credentials: f.credentials("assumerole")})
+	case "GetCallerIdentity":
+		xml.NewEncoder(w).Encode(fakeGetCallerIdentityResponse{
+			Account: fakeManagementAccountID,
+			Arn:     "arn:aws:sts::" + fakeManagementAccountID + ":assumed-role/test/test",
+			UserId:  "AROATEST:test",
+		})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		xml.NewEncoder(w).Encode(fakeErrorResponse{Code: "InvalidAction", Message: "unsupported action " + action})
+	}
+}
+
+// credentials returns new, already expired, temporary credentials.
+func (f *fakeAWS) credentials(prefix string) fakeCredentials {
+	return fakeCredentials{
+		AccessKeyId:     f.newID("ASIA" + strings.ToUpper(prefix)),
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+		Expiration:      time.Now().UTC().Add(-time.Minute).Format(time.RFC3339),
+	}
+}
+
+func (f *fakeAWS) describeStacks(w http.ResponseWriter, form map[string][]string) {
+	stackName := strings.Join(form["StackName"], "")
+	if err := f.injectedError("DescribeStacks", stackName); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		xml.NewEncoder(w).Encode(fakeErrorResponse{Code: err.code, Message: err.message})
+		return
+	}
+
+	for _, pp := range f.provisionedProducts {
+		if pp.stackID != stackName {
+			continue
+		}
+
+		stack := fakeStack{
+			StackId:      pp.stackID,
+			StackName:    "SC-000000000000-" + pp.id,
+			StackStatus:  "UPDATE_COMPLETE",
+			CreationTime: time.Now().UTC().Format(time.RFC3339),
+		}
+
+		keys := make([]string, 0, len(pp.parameters))
+		for key := range pp.parameters {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			stack.Parameters = append(stack.Parameters, fakeStackParameter{ParameterKey: key, ParameterValue: pp.parameters[key]})
+		}
+
+		xml.NewEncoder(w).Encode(fakeDescribeStacksResponse{Stacks: []fakeStack{stack}})
+		return
+	}
+
+	w.WriteHeader(http.StatusBadRequest)
+	xml.NewEncoder(w).Encode(fakeErrorResponse{Code: "ValidationError", Message: "Stack with id " + stackName + " does not exist"})
+}
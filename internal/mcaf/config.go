@@ -1,27 +1,120 @@
 package mcaf
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/account"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/codebuild"
 	"github.com/aws/aws-sdk-go/service/organizations"
 	"github.com/aws/aws-sdk-go/service/servicecatalog"
+	"github.com/aws/aws-sdk-go/service/sts"
 	awsbase "github.com/hashicorp/aws-sdk-go-base"
+	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	homedir "github.com/mitchellh/go-homedir"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// organizationsRequestRate and organizationsRequestBurst configure the client-side
+	// token bucket used when listing many resources, to stay below the request rate
+	// limits of the Organizations API.
+	organizationsRequestRate  = 10
+	organizationsRequestBurst = 5
+
+	// serviceCatalogRequestRate and serviceCatalogRequestBurst configure the client-side
+	// token bucket used when reading the outputs of many provisioned products, to stay
+	// below the request rate limits of the Service Catalog API.
+	serviceCatalogRequestRate  = 5
+	serviceCatalogRequestBurst = 5
 )
 
 // Client represents a general purpose MCAF client.
 type Client struct {
 	AWSClient *AWSClient
+
+	// provisioningQueue limits the number of concurrent account provisioning operations.
+	provisioningQueue *provisioningQueue
 }
 
 type AWSClient struct {
 	accountID string
-	cbconn    *codebuild.CodeBuild
-	orgsconn  *organizations.Organizations
-	scconn    *servicecatalog.ServiceCatalog
+	acctconn  accountAPI
+	cbconn    codeBuildAPI
+	cfconn    cloudFormationAPI
+	orgsconn  organizationsAPI
+	scconn    serviceCatalogAPI
+
+	// orgsLimiter limits the rate of Organizations API requests.
+	orgsLimiter *rate.Limiter
+
+	// scLimiter limits the rate of Service Catalog API requests.
+	scLimiter *rate.Limiter
+
+	// defaultTags are merged into the tags of all resources supporting tags.
+	defaultTags map[string]string
+}
+
+// accountAPI is the subset of the Account Management API used by the provider.
+type accountAPI interface {
+	DeleteAlternateContactWithContext(aws.Context, *account.DeleteAlternateContactInput, ...request.Option) (*account.DeleteAlternateContactOutput, error)
+	GetAlternateContactWithContext(aws.Context, *account.GetAlternateContactInput, ...request.Option) (*account.GetAlternateContactOutput, error)
+	PutAlternateContactWithContext(aws.Context, *account.PutAlternateContactInput, ...request.Option) (*account.PutAlternateContactOutput, error)
+}
+
+// codeBuildAPI is the subset of the CodeBuild API used by the provider.
+type codeBuildAPI interface {
+	StartBuildWithContext(aws.Context, *codebuild.StartBuildInput, ...request.Option) (*codebuild.StartBuildOutput, error)
+}
+
+// cloudFormationAPI is the subset of the CloudFormation API used by the provider.
+type cloudFormationAPI interface {
+	DescribeStacksWithContext(aws.Context, *cloudformation.DescribeStacksInput, ...request.Option) (*cloudformation.DescribeStacksOutput, error)
+}
+
+// organizationsAPI is the subset of the Organizations API used by the provider.
+type organizationsAPI interface {
+	CloseAccountWithContext(aws.Context, *organizations.CloseAccountInput, ...request.Option) (*organizations.CloseAccountOutput, error)
+	CreateOrganizationalUnitWithContext(aws.Context, *organizations.CreateOrganizationalUnitInput, ...request.Option) (*organizations.CreateOrganizationalUnitOutput, error)
+	DeleteOrganizationalUnitWithContext(aws.Context, *organizations.DeleteOrganizationalUnitInput, ...request.Option) (*organizations.DeleteOrganizationalUnitOutput, error)
+	DescribeAccountWithContext(aws.Context, *organizations.DescribeAccountInput, ...request.Option) (*organizations.DescribeAccountOutput, error)
+	DescribeOrganizationalUnitWithContext(aws.Context, *organizations.DescribeOrganizationalUnitInput, ...request.Option) (*organizations.DescribeOrganizationalUnitOutput, error)
+	ListAccountsPagesWithContext(aws.Context, *organizations.ListAccountsInput, func(*organizations.ListAccountsOutput, bool) bool, ...request.Option) error
+	ListAccountsForParentPagesWithContext(aws.Context, *organizations.ListAccountsForParentInput, func(*organizations.ListAccountsForParentOutput, bool) bool, ...request.Option) error
+	ListOrganizationalUnitsForParentPagesWithContext(aws.Context, *organizations.ListOrganizationalUnitsForParentInput, func(*organizations.ListOrganizationalUnitsForParentOutput, bool) bool, ...request.Option) error
+	ListParentsWithContext(aws.Context, *organizations.ListParentsInput, ...request.Option) (*organizations.ListParentsOutput, error)
+	ListRootsPagesWithContext(aws.Context, *organizations.ListRootsInput, func(*organizations.ListRootsOutput, bool) bool, ...request.Option) error
+	ListTagsForResourcePagesWithContext(aws.Context, *organizations.ListTagsForResourceInput, func(*organizations.ListTagsForResourceOutput, bool) bool, ...request.Option) error
+	MoveAccountWithContext(aws.Context, *organizations.MoveAccountInput, ...request.Option) (*organizations.MoveAccountOutput, error)
+	TagResourceWithContext(aws.Context, *organizations.TagResourceInput, ...request.Option) (*organizations.TagResourceOutput, error)
+	UntagResourceWithContext(aws.Context, *organizations.UntagResourceInput, ...request.Option) (*organizations.UntagResourceOutput, error)
+	UpdateOrganizationalUnitWithContext(aws.Context, *organizations.UpdateOrganizationalUnitInput, ...request.Option) (*organizations.UpdateOrganizationalUnitOutput, error)
+}
+
+// serviceCatalogAPI is the subset of the Service Catalog API used by the provider.
+type serviceCatalogAPI interface {
+	DescribeProductWithContext(aws.Context, *servicecatalog.DescribeProductInput, ...request.Option) (*servicecatalog.DescribeProductOutput, error)
+	DescribeProvisionedProductWithContext(aws.Context, *servicecatalog.DescribeProvisionedProductInput, ...request.Option) (*servicecatalog.DescribeProvisionedProductOutput, error)
+	DescribeRecordWithContext(aws.Context, *servicecatalog.DescribeRecordInput, ...request.Option) (*servicecatalog.DescribeRecordOutput, error)
+	GetProvisionedProductOutputsPagesWithContext(aws.Context, *servicecatalog.GetProvisionedProductOutputsInput, func(*servicecatalog.GetProvisionedProductOutputsOutput, bool) bool, ...request.Option) error
+	ListProvisioningArtifactsWithContext(aws.Context, *servicecatalog.ListProvisioningArtifactsInput, ...request.Option) (*servicecatalog.ListProvisioningArtifactsOutput, error)
+	ProvisionProductWithContext(aws.Context, *servicecatalog.ProvisionProductInput, ...request.Option) (*servicecatalog.ProvisionProductOutput, error)
+	SearchProductsWithContext(aws.Context, *servicecatalog.SearchProductsInput, ...request.Option) (*servicecatalog.SearchProductsOutput, error)
+	SearchProvisionedProductsPagesWithContext(aws.Context, *servicecatalog.SearchProvisionedProductsInput, func(*servicecatalog.SearchProvisionedProductsOutput, bool) bool, ...request.Option) error
+	SearchProvisionedProductsWithContext(aws.Context, *servicecatalog.SearchProvisionedProductsInput, ...request.Option) (*servicecatalog.SearchProvisionedProductsOutput, error)
+	TerminateProvisionedProductWithContext(aws.Context, *servicecatalog.TerminateProvisionedProductInput, ...request.Option) (*servicecatalog.TerminateProvisionedProductOutput, error)
+	UpdateProvisionedProductWithContext(aws.Context, *servicecatalog.UpdateProvisionedProductInput, ...request.Option) (*servicecatalog.UpdateProvisionedProductOutput, error)
 }
 
 // awsClient configures and returns a fully initialized AWSClient.
@@ -51,7 +144,32 @@ func awsClient(aws map[string]interface{}) (*AWSClient, error) {
 	}
 	config.CredsFilename = credsPath
 
-	sess, accountID, _, err := awsbase.GetSessionWithAccountIDAndPartition(config)
+	var endpoints map[string]interface{}
+	if v, ok := aws["endpoints"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+		endpoints = v[0].(map[string]interface{})
+	}
+	config.StsEndpoint = serviceEndpoint(endpoints, "sts")
+
+	if v, ok := aws["assume_role"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+		expandAssumeRole(config, v[0].(map[string]interface{}))
+	}
+
+	var defaultTags map[string]string
+	if v, ok := aws["default_tags"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+		tags := v[0].(map[string]interface{})["tags"].(map[string]interface{})
+		defaultTags = make(map[string]string, len(tags))
+		for key, value := range tags {
+			defaultTags[key] = value.(string)
+		}
+	}
+
+	var sess *session.Session
+	var accountID string
+	if v, ok := aws["assume_role_with_web_identity"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+		sess, accountID, err = webIdentitySession(config, v[0].(map[string]interface{}))
+	} else {
+		sess, accountID, _, err = awsbase.GetSessionWithAccountIDAndPartition(config)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -62,10 +180,172 @@ func awsClient(aws map[string]interface{}) (*AWSClient, error) {
 
 	client := &AWSClient{
 		accountID: accountID,
-		cbconn:    codebuild.New(sess.Copy()),
-		orgsconn:  organizations.New(sess.Copy()),
-		scconn:    servicecatalog.New(sess.Copy()),
+		acctconn:  account.New(sess.Copy(endpointConfig(endpoints, "account"))),
+		cbconn:    codebuild.New(sess.Copy(endpointConfig(endpoints, "codebuild"))),
+		cfconn:    cloudformation.New(sess.Copy(endpointConfig(endpoints, "cloudformation"))),
+		orgsconn:  organizations.New(sess.Copy(endpointConfig(endpoints, "organizations"))),
+		scconn:    servicecatalog.New(sess.Copy(endpointConfig(endpoints, "servicecatalog"))),
+
+		orgsLimiter: rate.NewLimiter(organizationsRequestRate, organizationsRequestBurst),
+		scLimiter:   rate.NewLimiter(serviceCatalogRequestRate, serviceCatalogRequestBurst),
+		defaultTags: defaultTags,
 	}
 
 	return client, nil
 }
+
+// endpointEnvVars maps the services supporting custom endpoints to the environment
+// variables that can be used to configure them.
+var endpointEnvVars = map[string]string{
+	"account":        "AWS_ENDPOINT_URL_ACCOUNT",
+	"cloudformation": "AWS_ENDPOINT_URL_CLOUDFORMATION",
+	"codebuild":      "AWS_ENDPOINT_URL_CODEBUILD",
+	"organizations":  "AWS_ENDPOINT_URL_ORGANIZATIONS",
+	"servicecatalog": "AWS_ENDPOINT_URL_SERVICE_CATALOG",
+	"sts":            "AWS_ENDPOINT_URL_STS",
+}
+
+// serviceEndpoint returns the custom endpoint of the service, either from the
+// endpoints configuration or from the environment.
+func serviceEndpoint(endpoints map[string]interface{}, service string) string {
+	if v, ok := endpoints[service].(string); ok && v != "" {
+		return v
+	}
+
+	return os.Getenv(endpointEnvVars[service])
+}
+
+// endpointConfig returns the AWS config used to override the endpoint of a service.
+func endpointConfig(endpoints map[string]interface{}, service string) *aws.Config {
+	endpoint := serviceEndpoint(endpoints, service)
+	if endpoint == "" {
+		return &aws.Config{}
+	}
+
+	log.Printf("[INFO] Setting custom %s endpoint: %s", service, endpoint)
+	return &aws.Config{Endpoint: aws.String(endpoint)}
+}
+
+// expandAssumeRole adds the assume role configuration to the AWS auth structure.
+func expandAssumeRole(config *awsbase.Config, m map[string]interface{}) {
+	config.AssumeRoleARN = m["role_arn"].(string)
+	config.AssumeRoleSessionName = m["session_name"].(string)
+	config.AssumeRoleExternalID = m["external_id"].(string)
+	config.AssumeRolePolicy = m["policy"].(string)
+
+	// The duration is validated by the schema.
+	if v := m["duration"].(string); v != "" {
+		duration, _ := time.ParseDuration(v)
+		config.AssumeRoleDurationSeconds = int(duration.Seconds())
+	}
+
+	if v, ok := m["tags"].(map[string]interface{}); ok && len(v) > 0 {
+		config.AssumeRoleTags = make(map[string]string, len(v))
+		for key, value := range v {
+			config.AssumeRoleTags[key] = value.(string)
+		}
+	}
+
+	if v, ok := m["transitive_tag_keys"].(*schema.Set); ok && v.Len() > 0 {
+		for _, key := range v.List() {
+			config.AssumeRoleTransitiveTagKeys = append(config.AssumeRoleTransitiveTagKeys, key.(string))
+		}
+	}
+}
+
+// webIdentitySession returns a session using the credentials of the role assumed with
+// a web identity token. When assume_role is configured as well, these credentials are
+// used to assume that role. The credentials are refreshed before they expire, as an
+// apply can take longer than the duration of a role session.
+func webIdentitySession(config *awsbase.Config, m map[string]interface{}) (*session.Session, string, error) {
+	roleARN := m["role_arn"].(string)
+
+	awsConfig := &aws.Config{
+		CredentialsChainVerboseErrors: aws.Bool(true),
+		EndpointResolver:              config.EndpointResolver(),
+		HTTPClient:                    cleanhttp.DefaultClient(),
+		MaxRetries:                    aws.Int(config.MaxRetries),
+		Region:                        aws.String(config.Region),
+	}
+
+	if config.DebugLogging {
+		awsConfig.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody | aws.LogDebugWithRequestRetries | aws.LogDebugWithRequestErrors)
+		awsConfig.Logger = awsbase.DebugLogger{}
+	}
+
+	stsSession, err := session.NewSession(awsConfig.Copy().WithCredentials(credentials.AnonymousCredentials))
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating web identity session: %w", err)
+	}
+
+	log.Printf("[INFO] Using AssumeRoleWithWebIdentity %s", roleARN)
+	creds := credentials.NewCredentials(stscreds.NewWebIdentityRoleProviderWithOptions(
+		sts.New(stsSession),
+		roleARN,
+		m["session_name"].(string),
+		stscreds.FetchTokenPath(m["web_identity_token_file"].(string)),
+		func(p *stscreds.WebIdentityRoleProvider) {
+			// The duration is validated by the schema.
+			if v := m["duration"].(string); v != "" {
+				p.Duration, _ = time.ParseDuration(v)
+			}
+		},
+	))
+
+	if config.AssumeRoleARN != "" {
+		assumeRoleSession, err := session.NewSession(awsConfig.Copy().WithCredentials(creds))
+		if err != nil {
+			return nil, "", fmt.Errorf("error creating assume role session: %w", err)
+		}
+
+		log.Printf("[INFO] Using AssumeRole %s with the web identity credentials", config.AssumeRoleARN)
+		creds = stscreds.NewCredentials(assumeRoleSession, config.AssumeRoleARN, func(p *stscreds.AssumeRoleProvider) {
+			expandAssumeRoleProvider(p, config)
+		})
+	}
+
+	sess, err := session.NewSession(awsConfig.Copy().WithCredentials(creds))
+	if err != nil {
+		return nil, "", fmt.Errorf("Error creating AWS session: %w", err)
+	}
+
+	// Add the same User-Agent products as sessions created by awsbase.
+	for i := len(config.UserAgentProducts) - 1; i >= 0; i-- {
+		product := config.UserAgentProducts[i]
+		sess.Handlers.Build.PushFront(request.MakeAddToUserAgentHandler(product.Name, product.Version, product.Extra...))
+	}
+
+	if config.SkipCredsValidation && config.SkipRequestingAccountId {
+		return sess, "", nil
+	}
+
+	accountID, _, err := awsbase.GetAccountIDAndPartitionFromSTSGetCallerIdentity(sts.New(sess))
+	if err != nil {
+		return nil, "", fmt.Errorf("error validating provider credentials: %w", err)
+	}
+
+	return sess, accountID, nil
+}
+
+// expandAssumeRoleProvider configures the assume role provider like awsbase does for
+// the assume role configuration of the AWS auth structure.
+func expandAssumeRoleProvider(p *stscreds.AssumeRoleProvider, config *awsbase.Config) {
+	if config.AssumeRoleSessionName != "" {
+		p.RoleSessionName = config.AssumeRoleSessionName
+	}
+	if config.AssumeRoleExternalID != "" {
+		p.ExternalID = aws.String(config.AssumeRoleExternalID)
+	}
+	if config.AssumeRolePolicy != "" {
+		p.Policy = aws.String(config.AssumeRolePolicy)
+	}
+	if config.AssumeRoleDurationSeconds > 0 {
+		p.Duration = time.Duration(config.AssumeRoleDurationSeconds) * time.Second
+	}
+	for key, value := range config.AssumeRoleTags {
+		p.Tags = append(p.Tags, &sts.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	if len(config.AssumeRoleTransitiveTagKeys) > 0 {
+		p.TransitiveTagKeys = aws.StringSlice(config.AssumeRoleTransitiveTagKeys)
+	}
+}
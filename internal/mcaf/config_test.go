@@ -0,0 +1,107 @@
+package mcaf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAWSClient_assumeRoleWithWebIdentity(t *testing.T) {
+	fake := newFakeAWS(t)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("oidc-token"), 0o600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	client, err := awsClient(map[string]interface{}{
+		"access_key":                  "",
+		"secret_key":                  "",
+		"profile":                     "",
+		"region":                      "eu-west-1",
+		"max_retries":                 0,
+		"shared_credentials_file":     "",
+		"skip_credentials_validation": false,
+		"skip_metadata_api_check":     true,
+		"skip_requesting_account_id":  false,
+		"token":                       "",
+		"assume_role": []interface{}{
+			map[string]interface{}{
+				"role_arn":     "arn:aws:iam::" + fakeManagementAccountID + ":role/ControlTowerAdmin",
+				"session_name": "terraform",
+				"external_id":  "",
+				"duration":     "",
+				"policy":       "",
+			},
+		},
+		"assume_role_with_web_identity": []interface{}{
+			map[string]interface{}{
+				"role_arn":                "arn:aws:iam::111111111111:role/ci",
+				"web_identity_token_file": tokenFile,
+				"session_name":            "ci",
+				"duration":                "15m",
+			},
+		},
+		"endpoints": []interface{}{
+			map[string]interface{}{
+				"organizations": fake.server.URL,
+				"sts":           fake.server.URL,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if client.accountID != fakeManagementAccountID {
+		t.Fatalf("expected account ID %s, got %s", fakeManagementAccountID, client.accountID)
+	}
+
+	// The fake issues expired credentials, so both roles are assumed again for every
+	// request instead of reusing the credentials obtained when configuring the provider.
+	for i := 0; i < 2; i++ {
+		if _, err := listRoots(context.Background(), client.orgsconn); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	if n := fake.requestCount("AssumeRoleWithWebIdentity"); n < 3 {
+		t.Fatalf("expected the web identity credentials to be refreshed, got %d requests", n)
+	}
+	if n := fake.requestCount("AssumeRole"); n < 3 {
+		t.Fatalf("expected the assume role credentials to be refreshed, got %d requests", n)
+	}
+	if key := fake.accessKeys["AssumeRole"]; !strings.HasPrefix(key, "ASIAWEBIDENTITY") {
+		t.Fatalf("expected AssumeRole to use the web identity credentials, got %s", key)
+	}
+	if key := fake.accessKeys["ListRoots"]; !strings.HasPrefix(key, "ASIAASSUMEROLE") {
+		t.Fatalf("expected ListRoots to use the assume role credentials, got %s", key)
+	}
+}
+
+func TestProvider_assumeRoleWithWebIdentityConflicts(t *testing.T) {
+	for _, key := range []string{"access_key", "secret_key", "token", "profile"} {
+		raw := map[string]interface{}{
+			"aws": []interface{}{
+				map[string]interface{}{
+					"region": "eu-west-1",
+					key:      "test",
+					"assume_role_with_web_identity": []interface{}{
+						map[string]interface{}{
+							"role_arn":                "arn:aws:iam::111111111111:role/ci",
+							"web_identity_token_file": "/tmp/token",
+						},
+					},
+				},
+			},
+		}
+
+		diags := New().Validate(terraform.NewResourceConfigRaw(raw))
+		if !diags.HasError() || !strings.Contains(diags[0].Detail, "conflicts with") {
+			t.Fatalf("%s: expected a conflict error, got %v", key, diags)
+		}
+	}
+}
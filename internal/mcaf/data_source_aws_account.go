@@ -0,0 +1,179 @@
+package mcaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/servicecatalog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// The Control Tower enrollment statuses of an account, derived from the status of
+// its Account Factory provisioned product.
+const (
+	enrollmentStatusEnrolled    = "ENROLLED"
+	enrollmentStatusInProgress  = "IN_PROGRESS"
+	enrollmentStatusFailed      = "FAILED"
+	enrollmentStatusNotEnrolled = "NOT_ENROLLED"
+)
+
+func dataSourceAwsAccount() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: checkProvider("aws", dataSourceAwsAccountRead),
+
+		Schema: map[string]*schema.Schema{
+			"account_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"account_id", "email", "name"},
+				ValidateFunc: validation.StringMatch(accountIDRegexp, "must be a 12 digit account ID"),
+			},
+			"email": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"account_id", "email", "name"},
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"account_id", "email", "name"},
+			},
+			"arn": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"enrollment_status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"organizational_unit_path": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"provisioned_product_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceAwsAccountRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	orgsconn := meta.(*Client).AWSClient.orgsconn
+	scconn := meta.(*Client).AWSClient.scconn
+
+	var attribute, value string
+	for _, k := range []string{"account_id", "email", "name"} {
+		if v, ok := d.GetOk(k); ok {
+			attribute, value = k, v.(string)
+			break
+		}
+	}
+
+	account, err := findOrganizationsAccount(ctx, orgsconn, attribute, value)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	accountID := aws.StringValue(account.Id)
+
+	ouPath, err := accountOrganizationalUnitPath(ctx, orgsconn, accountID)
+	if err != nil {
+		return diag.Errorf("Error reading organizational unit of account %s: %v", accountID, err)
+	}
+
+	// Accounts that are not enrolled in Control Tower don't have a provisioned product.
+	enrollmentStatus := enrollmentStatusNotEnrolled
+	ppID, err := findProvisionedAccount(ctx, scconn, meta.(*Client).AWSClient.scLimiter, accountID)
+	if err != nil && !errors.Is(err, errNoProvisionedAccount) {
+		return diag.FromErr(err)
+	}
+	if ppID != "" {
+		pp, err := scconn.DescribeProvisionedProductWithContext(ctx, &servicecatalog.DescribeProvisionedProductInput{
+			Id: aws.String(ppID),
+		})
+		if err != nil {
+			return diag.Errorf("Error reading provisioned account %s: %v", ppID, err)
+		}
+		enrollmentStatus = accountEnrollmentStatus(aws.StringValue(pp.ProvisionedProductDetail.Status))
+	}
+
+	d.SetId(accountID)
+	d.Set("account_id", accountID)
+	d.Set("arn", account.Arn)
+	d.Set("email", account.Email)
+	d.Set("enrollment_status", enrollmentStatus)
+	d.Set("name", account.Name)
+	d.Set("organizational_unit_path", ouPath)
+	d.Set("provisioned_product_id", ppID)
+	d.Set("status", account.Status)
+
+	return nil
+}
+
+// findOrganizationsAccount returns the only account in the organization with the given
+// account ID, email or name.
+func findOrganizationsAccount(ctx context.Context, conn organizationsAPI, attribute, value string) (*organizations.Account, error) {
+	var matches []*organizations.Account
+
+	log.Printf("[DEBUG] Search account with %s %s", attribute, value)
+	err := conn.ListAccountsPagesWithContext(ctx, &organizations.ListAccountsInput{}, func(page *organizations.ListAccountsOutput, lastPage bool) bool {
+		for _, account := range page.Accounts {
+			var match bool
+			switch attribute {
+			case "account_id":
+				match = aws.StringValue(account.Id) == value
+			case "email":
+				match = strings.EqualFold(aws.StringValue(account.Email), value)
+			case "name":
+				match = aws.StringValue(account.Name) == value
+			}
+			if match {
+				matches = append(matches, account)
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing accounts: %v", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("No account found with %s %s", attribute, value)
+	case 1:
+		return matches[0], nil
+	}
+
+	var ids []string
+	for _, account := range matches {
+		ids = append(ids, aws.StringValue(account.Id))
+	}
+
+	return nil, fmt.Errorf("Multiple accounts found with %s %s: %s", attribute, value, strings.Join(ids, ", "))
+}
+
+// accountEnrollmentStatus returns the enrollment status for the status of the provisioned product.
+func accountEnrollmentStatus(status string) string {
+	switch status {
+	case servicecatalog.ProvisionedProductStatusAvailable:
+		return enrollmentStatusEnrolled
+	case servicecatalog.ProvisionedProductStatusUnderChange, servicecatalog.ProvisionedProductStatusPlanInProgress:
+		return enrollmentStatusInProgress
+	default:
+		return enrollmentStatusFailed
+	}
+}
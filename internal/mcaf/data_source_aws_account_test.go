@@ -0,0 +1,108 @@
+package mcaf
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceAwsAccountRead(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	logging := fake.addAccount(fakeRootID, "logging", "logging@example.com")
+	fake.addAccount(workloads, "duplicate", "duplicate-1@example.com")
+	fake.addAccount(workloads, "duplicate", "duplicate-2@example.com")
+
+	meta := fake.client(t)
+
+	// Provision an account using the Account Factory, to enroll it in Control Tower.
+	provisioned := testResourceAWSAccountData(t, "Workloads")
+	if diags := resourceAWSAccountCreate(ctx, provisioned, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	cases := map[string]struct {
+		raw      map[string]interface{}
+		expected map[string]string
+		err      string
+	}{
+		"by name": {
+			raw: map[string]interface{}{"name": "logging"},
+			expected: map[string]string{
+				"account_id":               logging,
+				"email":                    "logging@example.com",
+				"enrollment_status":        enrollmentStatusNotEnrolled,
+				"organizational_unit_path": "Root",
+				"provisioned_product_id":   "",
+				"status":                   "ACTIVE",
+			},
+		},
+		"by email": {
+			raw: map[string]interface{}{"email": "TEST@example.com"},
+			expected: map[string]string{
+				"account_id":               provisioned.Get("account_id").(string),
+				"name":                     "test",
+				"enrollment_status":        enrollmentStatusEnrolled,
+				"organizational_unit_path": "Root/Workloads",
+				"provisioned_product_id":   provisioned.Id(),
+			},
+		},
+		"by account id": {
+			raw: map[string]interface{}{"account_id": logging},
+			expected: map[string]string{
+				"name": "logging",
+			},
+		},
+		"no match": {
+			raw: map[string]interface{}{"name": "missing"},
+			err: "No account found with name missing",
+		},
+		"multiple matches": {
+			raw: map[string]interface{}{"name": "duplicate"},
+			err: "Multiple accounts found with name duplicate",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceAwsAccount().Schema, tc.raw)
+
+			diags := dataSourceAwsAccountRead(ctx, d, meta)
+			if tc.err != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary, tc.err) {
+					t.Fatalf("expected error containing %q, got %v", tc.err, diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+
+			for k, v := range tc.expected {
+				if got := d.Get(k).(string); got != v {
+					t.Errorf("expected %s to be %q, got %q", k, v, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAccountEnrollmentStatus(t *testing.T) {
+	cases := map[string]string{
+		"AVAILABLE":        enrollmentStatusEnrolled,
+		"UNDER_CHANGE":     enrollmentStatusInProgress,
+		"PLAN_IN_PROGRESS": enrollmentStatusInProgress,
+		"TAINTED":          enrollmentStatusFailed,
+		"ERROR":            enrollmentStatusFailed,
+	}
+
+	for status, expected := range cases {
+		if got := accountEnrollmentStatus(status); got != expected {
+			t.Errorf("expected enrollment status %s for %s, got %s", expected, status, got)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package mcaf
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type Account struct {
+	Account *organizations.Account
+
+	// Path is the full path of the OU containing the account.
+	Path *string
+}
+
+func dataSourceAwsAccounts() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: checkProvider("aws", dataSourceAwsAccountsRead),
+
+		Schema: map[string]*schema.Schema{
+			"organizational_unit_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "Root",
+			},
+			"recursive": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"accounts": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"arn": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"email": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"joined_timestamp": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"organizational_unit_path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAwsAccountsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*Client).AWSClient.orgsconn
+
+	roots, err := listRoots(ctx, conn)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	parent, err := organizationalUnitOrRootByPath(ctx, conn, roots[0], d.Get("organizational_unit_path").(string))
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Organizational unit not found",
+			Detail:        err.Error(),
+			AttributePath: cty.GetAttrPath("organizational_unit_path"),
+		}}
+	}
+
+	// Without recursion only the accounts directly in the parent are listed.
+	maxDepth := strings.Count(aws.StringValue(parent.Path), "/")
+	if d.Get("recursive").(bool) {
+		maxDepth = defaultMaxOrganizationalUnitDepth
+	}
+
+	lister := &organizationalUnitLister{
+		conn:        conn,
+		concurrency: organizationalUnitListConcurrency,
+		limiter:     meta.(*Client).AWSClient.orgsLimiter,
+		maxDepth:    maxDepth,
+	}
+
+	accounts, err := lister.listAccounts(ctx, parent)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(aws.StringValue(parent.OrganizationalUnit.Id))
+
+	if err := d.Set("accounts", flattenOrganizationsAccounts(accounts)); err != nil {
+		return diag.Errorf("Error setting accounts: %s", err)
+	}
+
+	return nil
+}
+
+func flattenOrganizationsAccounts(accounts []*Account) []map[string]interface{} {
+	if len(accounts) == 0 {
+		return nil
+	}
+	var result []map[string]interface{}
+	for _, account := range accounts {
+		var joined string
+		if account.Account.JoinedTimestamp != nil {
+			joined = account.Account.JoinedTimestamp.Format(time.RFC3339)
+		}
+
+		result = append(result, map[string]interface{}{
+			"arn":                      aws.StringValue(account.Account.Arn),
+			"email":                    aws.StringValue(account.Account.Email),
+			"id":                       aws.StringValue(account.Account.Id),
+			"joined_timestamp":         joined,
+			"name":                     aws.StringValue(account.Account.Name),
+			"organizational_unit_path": aws.StringValue(account.Path),
+			"status":                   aws.StringValue(account.Account.Status),
+		})
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package mcaf
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestUnitDataSourceAwsAccounts_basic(t *testing.T) {
+	dataSourceName := "data.mcaf_aws_accounts.test"
+
+	fake := newFakeAWS(t)
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	prod := fake.addOU(workloads, "Prod")
+	shared := fake.addAccount(workloads, "shared", "shared@example.com")
+	fake.addAccount(prod, "prod", "prod@example.com")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testUnitPreCheck(t)
+		},
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fake.providerConfig() + `
+data "mcaf_aws_accounts" "test" {
+  organizational_unit_path = "Root/Workloads"
+  recursive                = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "accounts.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "accounts.0.id", shared),
+					resource.TestCheckResourceAttr(dataSourceName, "accounts.0.email", "shared@example.com"),
+					resource.TestCheckResourceAttr(dataSourceName, "accounts.0.status", "ACTIVE"),
+					resource.TestCheckResourceAttr(dataSourceName, "accounts.0.joined_timestamp", "2024-01-15T10:00:00Z"),
+					resource.TestCheckResourceAttr(dataSourceName, "accounts.1.organizational_unit_path", "Root/Workloads/Prod"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceAwsAccountsRead(t *testing.T) {
+	fake := newFakeAWS(t)
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	prod := fake.addOU(workloads, "Prod")
+	app := fake.addOU(prod, "App")
+	fake.addAccount(fakeRootID, "management", "management@example.com")
+	fake.addAccount(workloads, "shared", "shared@example.com")
+	fake.addAccount(prod, "prod", "prod@example.com")
+	fake.addAccount(app, "app", "app@example.com")
+
+	meta := fake.client(t)
+
+	cases := map[string]struct {
+		raw      map[string]interface{}
+		expected []string
+	}{
+		"root": {
+			raw:      map[string]interface{}{},
+			expected: []string{"management: Root"},
+		},
+		"root recursive": {
+			raw:      map[string]interface{}{"recursive": true},
+			expected: []string{"management: Root", "shared: Root/Workloads", "prod: Root/Workloads/Prod", "app: Root/Workloads/Prod/App"},
+		},
+		"organizational unit": {
+			raw:      map[string]interface{}{"organizational_unit_path": "Workloads/Prod"},
+			expected: []string{"prod: Root/Workloads/Prod"},
+		},
+		"organizational unit recursive": {
+			raw:      map[string]interface{}{"organizational_unit_path": "Root/Workloads/Prod", "recursive": true},
+			expected: []string{"prod: Root/Workloads/Prod", "app: Root/Workloads/Prod/App"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceAwsAccounts().Schema, tc.raw)
+			if diags := dataSourceAwsAccountsRead(context.Background(), d, meta); diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+
+			var accounts []string
+			for _, account := range d.Get("accounts").([]interface{}) {
+				m := account.(map[string]interface{})
+				accounts = append(accounts, m["name"].(string)+": "+m["organizational_unit_path"].(string))
+			}
+			if !reflect.DeepEqual(accounts, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, accounts)
+			}
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsAccounts().Schema, map[string]interface{}{
+		"organizational_unit_path": "Root/Workloads/Test",
+	})
+	if diags := dataSourceAwsAccountsRead(context.Background(), d, meta); !diags.HasError() {
+		t.Fatalf("expected an error for a missing organizational unit")
+	}
+}
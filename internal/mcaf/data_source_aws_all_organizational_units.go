@@ -1,35 +1,101 @@
 package mcaf
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"regexp"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 )
 
+// defaultMaxOrganizationalUnitDepth is the maximum number of nested OU levels supported
+// by Control Tower, which equals the limit of AWS Organizations.
+const defaultMaxOrganizationalUnitDepth = 5
+
 type OrganizationalUnit struct {
 	OrganizationalUnit *organizations.OrganizationalUnit
 
 	Path *string
+
+	ParentId   *string
+	ParentPath *string
+
+	// ChildOuCount and AccountCount are the number of direct children, or -1 if
+	// the children couldn't be listed.
+	ChildOuCount int
+	AccountCount int
 }
 
 func dataSourceAwsAllOrganizationalUnits() *schema.Resource {
 	return &schema.Resource{
-		Read: checkProvider("aws", dataSourceAwsAllOrganizationalUnitsRead),
+		ReadContext: checkProvider("aws", dataSourceAwsAllOrganizationalUnitsRead),
 
 		Schema: map[string]*schema.Schema{
+			"include_root": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"max_depth": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultMaxOrganizationalUnitDepth,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"parent_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"path_prefix"},
+			},
+			"partial_results_ok": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"path_prefix": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"parent_id"},
+			},
+			"by_path": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
 			"organizational_units": {
 				Type:     schema.TypeList,
 				Computed: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
+						"account_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
 						"arn": {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
+						"child_ou_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"depth": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
 						"id": {
 							Type:     schema.TypeString,
 							Computed: true,
@@ -38,6 +104,14 @@ func dataSourceAwsAllOrganizationalUnits() *schema.Resource {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
+						"parent_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"parent_path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
 						"path": {
 							Type:     schema.TypeString,
 							Computed: true,
@@ -49,28 +123,145 @@ func dataSourceAwsAllOrganizationalUnits() *schema.Resource {
 	}
 }
 
-func dataSourceAwsAllOrganizationalUnitsRead(d *schema.ResourceData, meta interface{}) error {
+func dataSourceAwsAllOrganizationalUnitsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	conn := meta.(*Client).AWSClient.orgsconn
 
-	roots, err := listRoots(conn)
+	roots, err := listRoots(ctx, conn)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Only the tree under the requested parent is listed.
+	parent, err := organizationalUnitsParent(ctx, conn, roots[0], d)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
-	root_id := aws.StringValue(roots[0].Id)
 
-	var ous []*OrganizationalUnit
-	ous, err = listOrganizationalUnitsForParentPagesRecursive(conn, "Root", root_id, ous)
+	lister := &organizationalUnitLister{
+		conn:             conn,
+		concurrency:      organizationalUnitListConcurrency,
+		limiter:          meta.(*Client).AWSClient.orgsLimiter,
+		maxDepth:         d.Get("max_depth").(int),
+		partialResultsOk: d.Get("partial_results_ok").(bool),
+	}
+
+	ous, err := lister.listOrganizationalUnits(ctx, parent)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	d.SetId(root_id)
+	if d.Get("include_root").(bool) {
+		ous = append([]*OrganizationalUnit{parent}, ous...)
+	}
+
+	if v, ok := d.GetOk("name_regex"); ok {
+		re := regexp.MustCompile(v.(string))
+
+		var filtered []*OrganizationalUnit
+		for _, ou := range ous {
+			if re.MatchString(aws.StringValue(ou.OrganizationalUnit.Name)) {
+				filtered = append(filtered, ou)
+			}
+		}
+		ous = filtered
+	}
+
+	d.SetId(aws.StringValue(parent.OrganizationalUnit.Id))
 
 	if err := d.Set("organizational_units", flattenOrganizationsOrganizationalUnits(ous)); err != nil {
-		return fmt.Errorf("Error setting organizational_units: %s", err)
+		return diag.Errorf("Error setting organizational_units: %s", err)
 	}
 
-	return nil
+	byPath := make(map[string]string, len(ous))
+	for _, ou := range ous {
+		byPath[aws.StringValue(ou.Path)] = aws.StringValue(ou.OrganizationalUnit.Id)
+	}
+	if err := d.Set("by_path", byPath); err != nil {
+		return diag.Errorf("Error setting by_path: %s", err)
+	}
+
+	var diags diag.Diagnostics
+	if len(lister.depthLimited) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Maximum depth of organizational units reached",
+			Detail: fmt.Sprintf("The organizational units under the following parents are nested deeper than max_depth (%d) and were skipped:\n\n  - ", lister.maxDepth) +
+				strings.Join(lister.depthLimited, "\n  - "),
+		})
+	}
+	if len(lister.skipped) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Incomplete list of organizational units",
+			Detail: "The organizational units under the following parents could not be listed and were skipped:\n\n  - " +
+				strings.Join(lister.skipped, "\n  - "),
+		})
+	}
+
+	return diags
+}
+
+// organizationalUnitsParent returns the root or organizational unit to list the
+// organizational units of, based on the parent_id or path_prefix arguments.
+func organizationalUnitsParent(ctx context.Context, conn organizationsAPI, root *organizations.Root, d *schema.ResourceData) (*OrganizationalUnit, error) {
+	rootID := aws.StringValue(root.Id)
+
+	if v, ok := d.GetOk("parent_id"); ok && v.(string) != rootID {
+		output, err := conn.DescribeOrganizationalUnitWithContext(ctx, &organizations.DescribeOrganizationalUnitInput{
+			OrganizationalUnitId: aws.String(v.(string)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing organizational unit %s: %v", v.(string), err)
+		}
+
+		// accountOrganizationalUnitPath returns the path of the parent of any child.
+		parentPath, err := accountOrganizationalUnitPath(ctx, conn, v.(string))
+		if err != nil {
+			return nil, err
+		}
+
+		return organizationalUnitWithParent(ctx, conn, output.OrganizationalUnit, parentPath)
+	}
+
+	return organizationalUnitOrRootByPath(ctx, conn, root, d.Get("path_prefix").(string))
+}
+
+// organizationalUnitOrRootByPath returns the organizational unit with the given path,
+// or the root if the path is empty or only contains the Root segment.
+func organizationalUnitOrRootByPath(ctx context.Context, conn organizationsAPI, root *organizations.Root, path string) (*OrganizationalUnit, error) {
+	if path := normalizeOrganizationalUnitPath(path); path != "" {
+		return findOrganizationalUnitByPath(ctx, conn, path, aws.StringValue(root.Id), "Root", false)
+	}
+
+	return &OrganizationalUnit{
+		OrganizationalUnit: &organizations.OrganizationalUnit{
+			Arn:  root.Arn,
+			Id:   root.Id,
+			Name: root.Name,
+		},
+		Path: aws.String("Root"),
+	}, nil
+}
+
+// organizationalUnitWithParent returns the organizational unit with the given parent
+// path, looking up the ID of its parent.
+func organizationalUnitWithParent(ctx context.Context, conn organizationsAPI, ou *organizations.OrganizationalUnit, parentPath string) (*OrganizationalUnit, error) {
+	parents, err := conn.ListParentsWithContext(ctx, &organizations.ListParentsInput{
+		ChildId: ou.Id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing parents of %s: %v", aws.StringValue(ou.Id), err)
+	}
+	if len(parents.Parents) == 0 {
+		return nil, fmt.Errorf("no parent found for %s", aws.StringValue(ou.Id))
+	}
+
+	return &OrganizationalUnit{
+		OrganizationalUnit: ou,
+		Path:               aws.String(parentPath + "/" + aws.StringValue(ou.Name)),
+		ParentId:           parents.Parents[0].Id,
+		ParentPath:         aws.String(parentPath),
+	}, nil
 }
 
 func flattenOrganizationsOrganizationalUnits(ous []*OrganizationalUnit) []map[string]interface{} {
@@ -80,56 +271,271 @@ func flattenOrganizationsOrganizationalUnits(ous []*OrganizationalUnit) []map[st
 	var result []map[string]interface{}
 	for _, ou := range ous {
 		result = append(result, map[string]interface{}{
-			"arn":  aws.StringValue(ou.OrganizationalUnit.Arn),
-			"id":   aws.StringValue(ou.OrganizationalUnit.Id),
-			"name": aws.StringValue(ou.OrganizationalUnit.Name),
-			"path": aws.StringValue(ou.Path),
+			"account_count":  ou.AccountCount,
+			"arn":            aws.StringValue(ou.OrganizationalUnit.Arn),
+			"child_ou_count": ou.ChildOuCount,
+			"depth":          strings.Count(aws.StringValue(ou.Path), "/"),
+			"id":             aws.StringValue(ou.OrganizationalUnit.Id),
+			"name":           aws.StringValue(ou.OrganizationalUnit.Name),
+			"parent_id":      aws.StringValue(ou.ParentId),
+			"parent_path":    aws.StringValue(ou.ParentPath),
+			"path":           aws.StringValue(ou.Path),
 		})
 	}
 	return result
 }
 
-func listOrganizationalUnitsForParentPagesRecursive(conn *organizations.Organizations, parentPath, parentId string, ous []*OrganizationalUnit) ([]*OrganizationalUnit, error) {
-	// Control Tower supports a maximum of 5 levels of nested OUs.
-	parentPathSplit := strings.Split(parentPath, "/")
-	if len(parentPathSplit) == 5 {
-		log.Printf("[INFO] Maximum number of levels of nested OUs reached. Skipping %s (%s)", parentPath, parentId)
-		return ous, nil
+// organizationalUnitListConcurrency is the maximum number of concurrent requests used
+// to list the organizational units.
+var organizationalUnitListConcurrency = 5
+
+// organizationalUnitLister recursively lists the organizational units under a parent.
+// The children of different parents are listed concurrently, but the organizational
+// units are always returned in depth-first order.
+type organizationalUnitLister struct {
+	conn organizationsAPI
+
+	// concurrency is the maximum number of concurrent requests.
+	concurrency int
+
+	// limiter limits the rate of requests, when set.
+	limiter *rate.Limiter
+
+	// maxDepth is the maximum number of nested OU levels to list.
+	maxDepth int
+
+	// partialResultsOk skips subtrees that can't be listed instead of failing.
+	partialResultsOk bool
+
+	// skipped contains the subtrees that were skipped because they couldn't be listed.
+	skipped []string
+
+	// depthLimited contains the parents whose children were skipped because of maxDepth.
+	depthLimited []string
+}
+
+// organizationalUnitNode is an organizational unit with its listed children.
+type organizationalUnitNode struct {
+	ou       *OrganizationalUnit
+	accounts []*organizations.Account
+	children []*organizationalUnitNode
+
+	// parent is set for all nodes except the node the listing started at.
+	parent *organizationalUnitNode
+
+	// skipped contains the error when the children couldn't be listed.
+	skipped error
+
+	// depthLimited is set when the children were skipped because of maxDepth.
+	depthLimited bool
+}
+
+// listOrganizationalUnits returns all organizational units under the parent, and
+// sets the number of children of the parent.
+func (l *organizationalUnitLister) listOrganizationalUnits(ctx context.Context, parent *OrganizationalUnit) ([]*OrganizationalUnit, error) {
+	root, err := l.walk(ctx, parent)
+	if err != nil {
+		return nil, err
 	}
 
-	input := &organizations.ListOrganizationalUnitsForParentInput{
-		ParentId: aws.String(parentId),
+	return l.flatten(root, nil), nil
+}
+
+// listAccounts returns all accounts in the parent and the organizational units under it.
+func (l *organizationalUnitLister) listAccounts(ctx context.Context, parent *OrganizationalUnit) ([]*Account, error) {
+	root, err := l.walk(ctx, parent)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Printf("[DEBUG] Listing OUs under parent: %s (%s)", parentPath, parentId)
-	err := conn.ListOrganizationalUnitsForParentPages(input, func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
-		for _, ou := range page.OrganizationalUnits {
-			ouPath := fmt.Sprintf("%s/%s", parentPath, aws.StringValue(ou.Name))
-			ous = append(ous, &OrganizationalUnit{
-				OrganizationalUnit: ou,
-				Path:               aws.String(ouPath),
-			})
+	return l.flattenAccounts(root, nil), nil
+}
 
-			var err error
-			ous, err = listOrganizationalUnitsForParentPagesRecursive(conn, ouPath, aws.StringValue(ou.Id), ous)
-			if err != nil {
-				log.Printf("[ERROR] Error listing OUs for %s (%s): %s", ouPath, aws.StringValue(ou.Id), err)
+// walk lists the tree of organizational units and accounts under the parent.
+func (l *organizationalUnitLister) walk(ctx context.Context, parent *OrganizationalUnit) (*organizationalUnitNode, error) {
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, max(l.concurrency, 1))
+
+	var visit func(node *organizationalUnitNode)
+	visit = func(node *organizationalUnitNode) {
+		g.Go(func() error {
+			if err := l.listChildren(ctx, sem, node); err != nil {
+				return err
+			}
+			for _, child := range node.children {
+				visit(child)
 			}
+			return nil
+		})
+	}
+
+	root := &organizationalUnitNode{ou: parent}
+	visit(root)
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// listChildren lists the child OUs and accounts of the node, waiting for a free slot
+// and the rate limiter.
+func (l *organizationalUnitLister) listChildren(ctx context.Context, sem chan struct{}, node *organizationalUnitNode) error {
+	select {
+	case sem <- struct{}{}:
+		defer func() { <-sem }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	path := aws.StringValue(node.ou.Path)
+	id := aws.StringValue(node.ou.OrganizationalUnit.Id)
+
+	log.Printf("[DEBUG] Listing OUs and accounts under parent: %s (%s)", path, id)
+	children, accounts, err := l.listChildrenPages(ctx, id)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// The parent must always be listed, there are no partial results without it.
+		if l.partialResultsOk && node.parent != nil {
+			log.Printf("[WARN] Skipping OUs under %s (%s): %s", path, id, err)
+			node.ou.ChildOuCount = -1
+			node.ou.AccountCount = -1
+			node.skipped = err
+			return nil
 		}
+		return fmt.Errorf("error listing Organization Units for parent %s (%s): %s", path, id, err)
+	}
 
-		return !lastPage
+	node.ou.ChildOuCount = len(children)
+	node.ou.AccountCount = len(accounts)
+	node.accounts = accounts
+
+	// The depth of the parent is the number of OUs in its path, the root not included.
+	if strings.Count(path, "/") >= l.maxDepth {
+		if len(children) > 0 {
+			log.Printf("[INFO] Maximum depth of nested OUs reached. Skipping OUs under %s (%s)", path, id)
+			node.depthLimited = true
+		}
+		return nil
+	}
+
+	for _, ou := range children {
+		node.children = append(node.children, &organizationalUnitNode{
+			ou: &OrganizationalUnit{
+				OrganizationalUnit: ou,
+				Path:               aws.String(fmt.Sprintf("%s/%s", path, aws.StringValue(ou.Name))),
+				ParentId:           aws.String(id),
+				ParentPath:         aws.String(path),
+			},
+			parent: node,
+		})
+	}
+
+	return nil
+}
+
+// listChildrenPages returns the child OUs and accounts of the parent.
+func (l *organizationalUnitLister) listChildrenPages(ctx context.Context, parentId string) ([]*organizations.OrganizationalUnit, []*organizations.Account, error) {
+	var children []*organizations.OrganizationalUnit
+	var accounts []*organizations.Account
+	var waitErr error
+
+	// Wait for the rate limiter before requesting every page.
+	if err := l.wait(ctx); err != nil {
+		return nil, nil, err
+	}
+	err := l.conn.ListOrganizationalUnitsForParentPagesWithContext(ctx, &organizations.ListOrganizationalUnitsForParentInput{
+		ParentId: aws.String(parentId),
+	}, func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
+		children = append(children, page.OrganizationalUnits...)
+		if lastPage {
+			return false
+		}
+		waitErr = l.wait(ctx)
+		return waitErr == nil
 	})
+	if err == nil {
+		err = waitErr
+	}
+	if err != nil {
+		return nil, nil, err
+	}
 
+	if err := l.wait(ctx); err != nil {
+		return nil, nil, err
+	}
+	err = l.conn.ListAccountsForParentPagesWithContext(ctx, &organizations.ListAccountsForParentInput{
+		ParentId: aws.String(parentId),
+	}, func(page *organizations.ListAccountsForParentOutput, lastPage bool) bool {
+		accounts = append(accounts, page.Accounts...)
+		if lastPage {
+			return false
+		}
+		waitErr = l.wait(ctx)
+		return waitErr == nil
+	})
+	if err == nil {
+		err = waitErr
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error listing Organization Units for parent (%s): %s", parentId, err)
+		return nil, nil, err
+	}
+
+	return children, accounts, nil
+}
+
+// flattenAccounts appends the accounts of the node and its children to accounts in
+// depth-first order.
+func (l *organizationalUnitLister) flattenAccounts(node *organizationalUnitNode, accounts []*Account) []*Account {
+	for _, account := range node.accounts {
+		accounts = append(accounts, &Account{
+			Account: account,
+			Path:    node.ou.Path,
+		})
+	}
+
+	for _, child := range node.children {
+		accounts = l.flattenAccounts(child, accounts)
+	}
+
+	return accounts
+}
+
+// wait blocks until the rate limiter allows another request.
+func (l *organizationalUnitLister) wait(ctx context.Context) error {
+	if l.limiter == nil {
+		return nil
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// flatten appends the listed children of the node to ous in depth-first order and
+// collects the skipped subtrees.
+func (l *organizationalUnitLister) flatten(node *organizationalUnitNode, ous []*OrganizationalUnit) []*OrganizationalUnit {
+	path := aws.StringValue(node.ou.Path)
+	id := aws.StringValue(node.ou.OrganizationalUnit.Id)
+
+	if node.skipped != nil {
+		l.skipped = append(l.skipped, fmt.Sprintf("%s (%s): %s", path, id, node.skipped))
+	}
+	if node.depthLimited {
+		l.depthLimited = append(l.depthLimited, fmt.Sprintf("%s (%s)", path, id))
+	}
+
+	for _, child := range node.children {
+		ous = append(ous, child.ou)
+		ous = l.flatten(child, ous)
 	}
 
-	return ous, nil
+	return ous
 }
 
-func listRoots(conn *organizations.Organizations) ([]*organizations.Root, error) {
+func listRoots(ctx context.Context, conn organizationsAPI) ([]*organizations.Root, error) {
 	var roots []*organizations.Root
-	err := conn.ListRootsPages(&organizations.ListRootsInput{}, func(page *organizations.ListRootsOutput, lastPage bool) bool {
+	err := conn.ListRootsPagesWithContext(ctx, &organizations.ListRootsInput{}, func(page *organizations.ListRootsOutput, lastPage bool) bool {
 		if page == nil {
 			return !lastPage
 		}
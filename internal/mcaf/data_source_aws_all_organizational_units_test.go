@@ -1,9 +1,18 @@
 package mcaf
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/organizations"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/time/rate"
 )
 
 func TestAccDataSourceAwsAllOrganizationalUnits_basic(t *testing.T) {
@@ -26,6 +35,325 @@ func TestAccDataSourceAwsAllOrganizationalUnits_basic(t *testing.T) {
 	})
 }
 
+func TestUnitDataSourceAwsAllOrganizationalUnits_basic(t *testing.T) {
+	dataSourceName := "data.mcaf_aws_all_organizational_units.test"
+
+	fake := newFakeAWS(t)
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	fake.addOU(workloads, "Prod")
+	fake.addOU(fakeRootID, "Security")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testUnitPreCheck(t)
+		},
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fake.providerConfig() + `data "mcaf_aws_all_organizational_units" "test" {}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "organizational_units.#", "3"),
+					resource.TestCheckResourceAttr(dataSourceName, "organizational_units.0.path", "Root/Workloads"),
+					resource.TestCheckResourceAttr(dataSourceName, "organizational_units.1.path", "Root/Workloads/Prod"),
+					resource.TestCheckResourceAttr(dataSourceName, "organizational_units.2.path", "Root/Security"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceAwsAllOrganizationalUnitsRead_filters(t *testing.T) {
+	fake := newFakeAWS(t)
+
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	fake.addOU(workloads, "Prod")
+	test := fake.addOU(workloads, "Test")
+	fake.addOU(test, "TestApp")
+	security := fake.addOU(fakeRootID, "Security")
+	fake.addOU(security, "Audit")
+
+	meta := fake.client(t)
+
+	cases := map[string]struct {
+		raw      map[string]interface{}
+		expected []string
+		requests int
+	}{
+		"all": {
+			raw:      map[string]interface{}{},
+			expected: []string{"Root/Workloads", "Root/Workloads/Prod", "Root/Workloads/Test", "Root/Workloads/Test/TestApp", "Root/Security", "Root/Security/Audit"},
+			requests: 7,
+		},
+		"path prefix": {
+			raw:      map[string]interface{}{"path_prefix": "Root/Workloads"},
+			expected: []string{"Root/Workloads/Prod", "Root/Workloads/Test", "Root/Workloads/Test/TestApp"},
+			requests: 5,
+		},
+		"path prefix without root and including the root": {
+			raw:      map[string]interface{}{"path_prefix": "Workloads/Test", "include_root": true},
+			expected: []string{"Root/Workloads/Test", "Root/Workloads/Test/TestApp"},
+			requests: 4,
+		},
+		"parent id": {
+			raw:      map[string]interface{}{"parent_id": security},
+			expected: []string{"Root/Security/Audit"},
+			requests: 2,
+		},
+		"include root": {
+			raw:      map[string]interface{}{"include_root": true, "max_depth": 1},
+			expected: []string{"Root", "Root/Workloads", "Root/Security"},
+			requests: 3,
+		},
+		"name regex": {
+			raw:      map[string]interface{}{"name_regex": "^Test"},
+			expected: []string{"Root/Workloads/Test", "Root/Workloads/Test/TestApp"},
+			requests: 7,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			before := fake.requestCount("ListOrganizationalUnitsForParent")
+
+			d := schema.TestResourceDataRaw(t, dataSourceAwsAllOrganizationalUnits().Schema, tc.raw)
+			if diags := dataSourceAwsAllOrganizationalUnitsRead(context.Background(), d, meta); diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+
+			var paths []string
+			for _, ou := range d.Get("organizational_units").([]interface{}) {
+				paths = append(paths, ou.(map[string]interface{})["path"].(string))
+			}
+			if !reflect.DeepEqual(paths, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, paths)
+			}
+
+			if requests := fake.requestCount("ListOrganizationalUnitsForParent") - before; requests != tc.requests {
+				t.Fatalf("expected %d requests to list OUs, got %d", tc.requests, requests)
+			}
+		})
+	}
+}
+
+func TestDataSourceAwsAllOrganizationalUnitsRead_attributes(t *testing.T) {
+	fake := newFakeAWS(t)
+
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	prod := fake.addOU(workloads, "Prod")
+	fake.addOU(workloads, "Test")
+	fake.addAccount(prod, "prod-1", "prod-1@example.com")
+	fake.addAccount(prod, "prod-2", "prod-2@example.com")
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsAllOrganizationalUnits().Schema, map[string]interface{}{
+		"path_prefix":  "Workloads",
+		"include_root": true,
+	})
+	if diags := dataSourceAwsAllOrganizationalUnitsRead(context.Background(), d, fake.client(t)); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	expected := map[string]string{
+		"organizational_units.#":                "3",
+		"organizational_units.0.path":           "Root/Workloads",
+		"organizational_units.0.parent_id":      fakeRootID,
+		"organizational_units.0.parent_path":    "Root",
+		"organizational_units.0.depth":          "1",
+		"organizational_units.0.child_ou_count": "2",
+		"organizational_units.0.account_count":  "0",
+		"organizational_units.1.path":           "Root/Workloads/Prod",
+		"organizational_units.1.parent_id":      workloads,
+		"organizational_units.1.parent_path":    "Root/Workloads",
+		"organizational_units.1.depth":          "2",
+		"organizational_units.1.child_ou_count": "0",
+		"organizational_units.1.account_count":  "2",
+		"by_path.%":                             "3",
+		"by_path.Root/Workloads/Prod":           prod,
+	}
+
+	state := d.State()
+	for k, v := range expected {
+		if got := state.Attributes[k]; got != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestListOrganizationalUnits(t *testing.T) {
+	fake := newFakeAWS(t)
+	fake.pageSize = 1
+
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	fake.addOU(workloads, "Prod")
+	fake.addOU(workloads, "Test")
+	fake.addOU(fakeRootID, "Security")
+
+	lister := &organizationalUnitLister{conn: fake.client(t).AWSClient.orgsconn, concurrency: 1, maxDepth: defaultMaxOrganizationalUnitDepth}
+
+	ous, err := lister.listOrganizationalUnits(context.Background(), testFakeRootOrganizationalUnit())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{"Root/Workloads", "Root/Workloads/Prod", "Root/Workloads/Test", "Root/Security"}
+	if len(ous) != len(expected) {
+		t.Fatalf("expected %d OUs, got %d", len(expected), len(ous))
+	}
+	for i, path := range expected {
+		if got := *ous[i].Path; got != path {
+			t.Fatalf("expected OU %d to have path %s, got %s", i, path, got)
+		}
+	}
+}
+
+func TestListOrganizationalUnits_error(t *testing.T) {
+	fake := newFakeAWS(t)
+
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	prod := fake.addOU(workloads, "Prod")
+	fake.addOU(prod, "App")
+	fake.addOU(fakeRootID, "Security")
+	fake.errors["ListOrganizationalUnitsForParent/"+prod] = "AccessDeniedException"
+
+	conn := fake.client(t).AWSClient.orgsconn
+
+	lister := &organizationalUnitLister{conn: conn, maxDepth: defaultMaxOrganizationalUnitDepth}
+	if _, err := lister.listOrganizationalUnits(context.Background(), testFakeRootOrganizationalUnit()); err == nil || !strings.Contains(err.Error(), "Root/Workloads/Prod") {
+		t.Fatalf("expected error listing Root/Workloads/Prod, got %v", err)
+	}
+
+	lister = &organizationalUnitLister{conn: conn, maxDepth: defaultMaxOrganizationalUnitDepth, partialResultsOk: true}
+	ous, err := lister.listOrganizationalUnits(context.Background(), testFakeRootOrganizationalUnit())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(ous) != 3 {
+		t.Fatalf("expected 3 OUs, got %d", len(ous))
+	}
+	if len(lister.skipped) != 1 || !strings.HasPrefix(lister.skipped[0], "Root/Workloads/Prod ("+prod+")") {
+		t.Fatalf("expected Root/Workloads/Prod to be skipped, got %v", lister.skipped)
+	}
+}
+
+func TestListOrganizationalUnits_maxDepth(t *testing.T) {
+	fake := newFakeAWS(t)
+
+	// Create the maximum of 5 nested levels supported by Control Tower.
+	parent := fakeRootID
+	for _, name := range []string{"L1", "L2", "L3", "L4", "L5"} {
+		parent = fake.addOU(parent, name)
+	}
+
+	conn := fake.client(t).AWSClient.orgsconn
+
+	lister := &organizationalUnitLister{conn: conn, maxDepth: defaultMaxOrganizationalUnitDepth}
+	ous, err := lister.listOrganizationalUnits(context.Background(), testFakeRootOrganizationalUnit())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(ous) != 5 || *ous[4].Path != "Root/L1/L2/L3/L4/L5" {
+		t.Fatalf("expected 5 levels of OUs, got %d", len(ous))
+	}
+	if len(lister.depthLimited) != 0 {
+		t.Fatalf("expected no depth limited OUs, got %v", lister.depthLimited)
+	}
+
+	lister = &organizationalUnitLister{conn: conn, maxDepth: 3}
+	ous, err = lister.listOrganizationalUnits(context.Background(), testFakeRootOrganizationalUnit())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(ous) != 3 {
+		t.Fatalf("expected 3 levels of OUs, got %d", len(ous))
+	}
+	if len(lister.depthLimited) != 1 || !strings.HasPrefix(lister.depthLimited[0], "Root/L1/L2/L3 ") {
+		t.Fatalf("expected Root/L1/L2/L3 to be depth limited, got %v", lister.depthLimited)
+	}
+}
+
+func TestListOrganizationalUnits_concurrency(t *testing.T) {
+	fake := newFakeAWS(t)
+	fake.pageSize = 2
+	fake.latency = time.Millisecond
+
+	expected := testAddOrganizationalUnitTree(fake, fakeRootID, "Root", 4, 3)
+	conn := fake.client(t).AWSClient.orgsconn
+
+	for _, concurrency := range []int{1, 4, 16} {
+		lister := &organizationalUnitLister{
+			conn:        conn,
+			concurrency: concurrency,
+			limiter:     rate.NewLimiter(rate.Inf, 0),
+			maxDepth:    defaultMaxOrganizationalUnitDepth,
+		}
+
+		ous, err := lister.listOrganizationalUnits(context.Background(), testFakeRootOrganizationalUnit())
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		var paths []string
+		for _, ou := range ous {
+			paths = append(paths, *ou.Path)
+		}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Fatalf("expected OUs in depth-first order with concurrency %d, got %v", concurrency, paths)
+		}
+	}
+}
+
+func BenchmarkListOrganizationalUnits(b *testing.B) {
+	fake := newFakeAWS(b)
+	fake.latency = 5 * time.Millisecond
+
+	// 4 levels of 8 OUs each is 4680 OUs.
+	testAddOrganizationalUnitTree(fake, fakeRootID, "Root", 4, 8)
+	conn := fake.client(b).AWSClient.orgsconn
+
+	for _, concurrency := range []int{1, organizationalUnitListConcurrency, 20} {
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				lister := &organizationalUnitLister{
+					conn:        conn,
+					concurrency: concurrency,
+					limiter:     rate.NewLimiter(rate.Inf, 0),
+					maxDepth:    defaultMaxOrganizationalUnitDepth,
+				}
+				if _, err := lister.listOrganizationalUnits(context.Background(), testFakeRootOrganizationalUnit()); err != nil {
+					b.Fatalf("err: %s", err)
+				}
+			}
+		})
+	}
+}
+
+// testFakeRootOrganizationalUnit returns the root of the fake backend.
+func testFakeRootOrganizationalUnit() *OrganizationalUnit {
+	return &OrganizationalUnit{
+		OrganizationalUnit: &organizations.OrganizationalUnit{Id: aws.String(fakeRootID), Name: aws.String("Root")},
+		Path:               aws.String("Root"),
+	}
+}
+
+// testAddOrganizationalUnitTree adds a tree of organizational units with the given depth
+// and number of children per OU, and returns their paths in depth-first order.
+func testAddOrganizationalUnitTree(fake *fakeAWS, parentID, parentPath string, depth, children int) []string {
+	var paths []string
+	if depth == 0 {
+		return paths
+	}
+
+	for i := 0; i < children; i++ {
+		name := fmt.Sprintf("OU%d", i)
+		path := parentPath + "/" + name
+
+		id := fake.addOU(parentID, name)
+		paths = append(paths, path)
+		paths = append(paths, testAddOrganizationalUnitTree(fake, id, path, depth-1, children)...)
+	}
+
+	return paths
+}
+
 const testAccDataSourceAwsAllOrganizationalUnitsConfig = `
 provider "mcaf" {
   aws {}
@@ -0,0 +1,104 @@
+package mcaf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAwsOrganizationalUnit() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: checkProvider("aws", dataSourceAwsOrganizationalUnitRead),
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+			},
+			"case_insensitive": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"arn": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"parent_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"tags": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAwsOrganizationalUnitRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*Client).AWSClient.orgsconn
+
+	roots, err := listRoots(ctx, conn)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	path := d.Get("path").(string)
+
+	ou, err := findOrganizationalUnitByPath(ctx, conn, path, aws.StringValue(roots[0].Id), "Root", d.Get("case_insensitive").(bool))
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Organizational unit not found",
+			Detail:        err.Error(),
+			AttributePath: cty.GetAttrPath("path"),
+		}}
+	}
+
+	tags, err := listTags(ctx, conn, aws.StringValue(ou.OrganizationalUnit.Id))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(aws.StringValue(ou.OrganizationalUnit.Id))
+	d.Set("arn", ou.OrganizationalUnit.Arn)
+	d.Set("name", ou.OrganizationalUnit.Name)
+	d.Set("parent_id", ou.ParentId)
+
+	if err := d.Set("tags", tags); err != nil {
+		return diag.Errorf("Error setting tags: %s", err)
+	}
+
+	return nil
+}
+
+// listTags returns the tags of the Organizations resource.
+func listTags(ctx context.Context, conn organizationsAPI, resourceID string) (map[string]string, error) {
+	tags := make(map[string]string)
+	err := conn.ListTagsForResourcePagesWithContext(ctx, &organizations.ListTagsForResourceInput{
+		ResourceId: aws.String(resourceID),
+	}, func(page *organizations.ListTagsForResourceOutput, lastPage bool) bool {
+		for _, tag := range page.Tags {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing tags of %s: %v", resourceID, err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,127 @@
+package mcaf
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestUnitDataSourceAwsOrganizationalUnit_basic(t *testing.T) {
+	dataSourceName := "data.mcaf_aws_organizational_unit.test"
+
+	fake := newFakeAWS(t)
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	prod := fake.addOU(workloads, "Prod")
+	fake.setTags(prod, map[string]string{"Environment": "production"})
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testUnitPreCheck(t)
+		},
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fake.providerConfig() + `
+data "mcaf_aws_organizational_unit" "test" {
+  path = "Root/Workloads/Prod"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "id", prod),
+					resource.TestCheckResourceAttr(dataSourceName, "name", "Prod"),
+					resource.TestCheckResourceAttr(dataSourceName, "parent_id", workloads),
+					resource.TestCheckResourceAttr(dataSourceName, "tags.Environment", "production"),
+				),
+			},
+			{
+				Config: fake.providerConfig() + `
+data "mcaf_aws_organizational_unit" "test" {
+  path = "Root/Workloads/Prd"
+}
+`,
+				ExpectError: regexp.MustCompile("did you mean: Prod"),
+			},
+		},
+	})
+}
+
+func TestDataSourceAwsOrganizationalUnitRead(t *testing.T) {
+	fake := newFakeAWS(t)
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	prod := fake.addOU(workloads, "Prod")
+	fake.addOU(workloads, "Production")
+	fake.addOU(workloads, "Test")
+	fake.setTags(prod, map[string]string{"Environment": "production"})
+
+	meta := fake.client(t)
+
+	cases := map[string]struct {
+		raw      map[string]interface{}
+		expected string
+		err      string
+	}{
+		"with root": {
+			raw:      map[string]interface{}{"path": "Root/Workloads/Prod"},
+			expected: prod,
+		},
+		"without root": {
+			raw:      map[string]interface{}{"path": "Workloads/Prod"},
+			expected: prod,
+		},
+		"case insensitive": {
+			raw:      map[string]interface{}{"path": "root/workloads/PROD", "case_insensitive": true},
+			expected: prod,
+		},
+		"case sensitive": {
+			raw: map[string]interface{}{"path": "Root/workloads/Prod"},
+			err: "organizational unit workloads not found in parent Root (r-root), did you mean: Workloads?",
+		},
+		"did you mean": {
+			raw: map[string]interface{}{"path": "Root/Workloads/Prd"},
+			err: "did you mean: Prod, Test, Production?",
+		},
+		"no children": {
+			raw: map[string]interface{}{"path": "Root/Workloads/Prod/App"},
+			err: "which has no organizational units",
+		},
+		"root": {
+			raw: map[string]interface{}{"path": "Root"},
+			err: "does not contain any organizational units",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceAwsOrganizationalUnit().Schema, tc.raw)
+
+			diags := dataSourceAwsOrganizationalUnitRead(context.Background(), d, meta)
+			if tc.err != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Detail, tc.err) {
+					t.Fatalf("expected error containing %q, got %v", tc.err, diags)
+				}
+				if !diags[0].AttributePath.Equals(cty.GetAttrPath("path")) {
+					t.Fatalf("expected error for path, got %#v", diags[0].AttributePath)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("err: %v", diags)
+			}
+
+			if d.Id() != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, d.Id())
+			}
+			if v := d.Get("parent_id").(string); v != workloads {
+				t.Fatalf("expected parent %s, got %s", workloads, v)
+			}
+			if v := d.Get("tags.Environment").(string); v != "production" {
+				t.Fatalf("expected Environment tag production, got %q", v)
+			}
+		})
+	}
+}
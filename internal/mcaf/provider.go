@@ -1,9 +1,13 @@
 package mcaf
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 // New returns a schema.Provider.
@@ -16,28 +20,42 @@ func New() *schema.Provider {
 				MaxItems: 1,
 				Elem:     awsProviderSchema(),
 			},
+
+			"account_provisioning_concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
+			"mcaf_aws_account":                  dataSourceAwsAccount(),
+			"mcaf_aws_accounts":                 dataSourceAwsAccounts(),
 			"mcaf_aws_all_organizational_units": dataSourceAwsAllOrganizationalUnits(),
+			"mcaf_aws_organizational_unit":      dataSourceAwsOrganizationalUnit(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"mcaf_aws_account":           resourceAWSAccount(),
-			"mcaf_aws_codebuild_trigger": resourceAWSCodeBuildTrigger(),
+			"mcaf_aws_account":                   resourceAWSAccount(),
+			"mcaf_aws_account_alternate_contact": resourceAWSAccountAlternateContact(),
+			"mcaf_aws_codebuild_trigger":         resourceAWSCodeBuildTrigger(),
+			"mcaf_aws_organizational_unit":       resourceAWSOrganizationalUnit(),
 		},
 
-		ConfigureFunc: providerConfigure,
+		ConfigureContextFunc: providerConfigure,
 	}
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
-	mcaf := &Client{}
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	mcaf := &Client{
+		provisioningQueue: newProvisioningQueue(d.Get("account_provisioning_concurrency").(int)),
+	}
 
 	if aws, ok := d.GetOk("aws"); ok {
 		client, err := awsClient(aws.([]interface{})[0].(map[string]interface{}))
 		if err != nil {
-			return nil, err
+			return nil, diag.FromErr(err)
 		}
 		mcaf.AWSClient = client
 	}
@@ -45,20 +63,20 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	return mcaf, nil
 }
 
-func checkProvider(p string, f func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
-	return func(d *schema.ResourceData, meta interface{}) error {
+func checkProvider(p string, f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 		mcaf := meta.(*Client)
 
 		switch p {
 		case "aws":
 			if mcaf.AWSClient == nil {
-				return fmt.Errorf("Missing AWS provider configuration")
+				return diag.Errorf("Missing AWS provider configuration")
 			}
 		default:
-			return fmt.Errorf("Trying to use unknown provider: %s", p)
+			return diag.Errorf("Trying to use unknown provider: %s", p)
 		}
 
-		return f(d, meta)
+		return f(ctx, d, meta)
 	}
 }
 
@@ -71,12 +89,119 @@ func awsProviderSchema() *schema.Resource {
 				Default:  "",
 			},
 
+			"assume_role": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"role_arn": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"session_name": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+
+						"external_id": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+
+						"duration": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "",
+							ValidateFunc: validateDuration,
+						},
+
+						"policy": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "",
+							ValidateFunc: validation.StringIsJSON,
+						},
+
+						"tags": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+
+						"transitive_tag_keys": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+
+			"assume_role_with_web_identity": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"aws.0.access_key", "aws.0.secret_key", "aws.0.token", "aws.0.profile"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"role_arn": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"web_identity_token_file": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+
+						"session_name": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "",
+						},
+
+						"duration": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "",
+							ValidateFunc: validateDuration,
+						},
+					},
+				},
+			},
+
 			"secret_key": {
 				Type:     schema.TypeString,
 				Optional: true,
 				Default:  "",
 			},
 
+			"endpoints": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem:     endpointsSchema(),
+			},
+
+			"default_tags": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tags": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+
 			"profile": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -134,3 +259,32 @@ func awsProviderSchema() *schema.Resource {
 		},
 	}
 }
+
+func endpointsSchema() *schema.Resource {
+	endpoints := map[string]*schema.Schema{}
+
+	for service := range endpointEnvVars {
+		endpoints[service] = &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "",
+			ValidateFunc: validation.Any(validation.StringIsEmpty, validation.IsURLWithHTTPorHTTPS),
+		}
+	}
+
+	return &schema.Resource{
+		Schema: endpoints,
+	}
+}
+
+func validateDuration(v interface{}, k string) (ws []string, errors []error) {
+	if v.(string) == "" {
+		return
+	}
+
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a valid duration like 1h or 30m: %v", k, err))
+	}
+
+	return
+}
@@ -2,7 +2,9 @@ package mcaf
 
 import (
 	"os"
+	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -15,6 +17,11 @@ const (
 var testAccProviderFactories map[string]func() (*schema.Provider, error)
 
 func init() {
+	// Don't wait long between polls when testing against the fake AWS backend.
+	provisioningMinPollDelay = 10 * time.Millisecond
+	provisioningMaxPollDelay = 50 * time.Millisecond
+	accountClosePollInterval = 10 * time.Millisecond
+
 	// Always allocate a new provider instance each invocation, otherwise gRPC
 	// ProviderConfigure() can overwrite configuration during concurrent testing.
 	testAccProviderFactories = map[string]func() (*schema.Provider, error){
@@ -41,6 +48,16 @@ func testAccAwsPreCheck(t *testing.T) {
 	}
 }
 
+// testUnitPreCheck skips unit tests that need the Terraform CLI when it isn't available.
+func testUnitPreCheck(t *testing.T) {
+	if os.Getenv("TF_ACC_TERRAFORM_PATH") != "" || os.Getenv("TF_ACC_TERRAFORM_VERSION") != "" {
+		return
+	}
+	if _, err := exec.LookPath("terraform"); err != nil {
+		t.Skip("terraform not found in PATH, set TF_ACC_TERRAFORM_PATH or TF_ACC_TERRAFORM_VERSION to run this test")
+	}
+}
+
 // testAccAwsClient configures and returns a fully initialized AWSClient.
 func testAccAwsClient() (*AWSClient, error) {
 	region := "us-east-1"
@@ -0,0 +1,151 @@
+package mcaf
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/servicecatalog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+)
+
+// provisioningQueueLogInterval is the interval used to log the queue position of
+// operations waiting for a provisioning slot.
+var provisioningQueueLogInterval = time.Minute
+
+// provisioningQueue limits the number of concurrent account provisioning operations.
+// Waiting operations are served in the order they were queued.
+type provisioningQueue struct {
+	mu      sync.Mutex
+	limit   int
+	active  int
+	waiting []chan struct{}
+}
+
+// newProvisioningQueue returns a provisioningQueue allowing the given number of
+// concurrent provisioning operations.
+func newProvisioningQueue(concurrency int) *provisioningQueue {
+	return &provisioningQueue{limit: concurrency}
+}
+
+// acquire blocks until a provisioning slot is available or the context is done. When
+// a slot was acquired, release must be called once the provisioning is finished.
+func (q *provisioningQueue) acquire(ctx context.Context, name string) error {
+	q.mu.Lock()
+	if q.active < q.limit && len(q.waiting) == 0 {
+		q.active++
+		q.mu.Unlock()
+		log.Printf("[DEBUG] Acquired provisioning slot for account %s", name)
+		return nil
+	}
+
+	ready := make(chan struct{})
+	q.waiting = append(q.waiting, ready)
+	log.Printf("[INFO] Account %s is queued for provisioning (queue position %d, %d running)", name, len(q.waiting), q.active)
+	q.mu.Unlock()
+
+	ticker := time.NewTicker(provisioningQueueLogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ready:
+			log.Printf("[INFO] Account %s acquired a provisioning slot", name)
+			return nil
+		case <-ticker.C:
+			if position := q.position(ready); position > 0 {
+				log.Printf("[INFO] Account %s is still queued for provisioning (queue position %d)", name, position)
+			}
+		case <-ctx.Done():
+			if !q.dequeue(ready) {
+				// The slot was handed over while the context was done, so give it back.
+				q.release()
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// release frees a provisioning slot, handing it over to the first queued operation.
+func (q *provisioningQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.waiting) > 0 {
+		close(q.waiting[0])
+		q.waiting = q.waiting[1:]
+		return
+	}
+
+	q.active--
+}
+
+// position returns the 1-based queue position of the waiting operation, or 0 if
+// it is not queued anymore.
+func (q *provisioningQueue) position(ready chan struct{}) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, w := range q.waiting {
+		if w == ready {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// dequeue removes the waiting operation from the queue and reports whether it was
+// still queued.
+func (q *provisioningQueue) dequeue(ready chan struct{}) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, w := range q.waiting {
+		if w == ready {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryWhenInProgress calls f and retries it for as long as Service Catalog or
+// Control Tower report that another operation is still in progress.
+func retryWhenInProgress(ctx context.Context, timeout time.Duration, name string, f func() error) error {
+	return retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		err := f()
+		if isInProgressError(err) {
+			log.Printf("[INFO] Another operation is in progress, retrying account %s: %v", name, err)
+			return retry.RetryableError(err)
+		}
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		return nil
+	})
+}
+
+// isInProgressError returns true if the error indicates another operation is in progress.
+// Service Catalog reports a conflicting operation on the provisioned product as a
+// ResourceInUseException, and Control Tower reports a conflicting landing zone or
+// Account Factory operation as an InvalidStateException.
+func isInProgressError(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+
+	switch awsErr.Code() {
+	case servicecatalog.ErrCodeResourceInUseException:
+		return true
+	case servicecatalog.ErrCodeInvalidStateException:
+		return strings.Contains(strings.ToLower(awsErr.Message()), "in progress")
+	default:
+		return false
+	}
+}
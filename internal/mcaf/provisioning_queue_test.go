@@ -0,0 +1,127 @@
+package mcaf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/servicecatalog"
+)
+
+func TestProvisioningQueue_order(t *testing.T) {
+	q := newProvisioningQueue(1)
+	ctx := context.Background()
+
+	if err := q.acquire(ctx, "first"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	order := make(chan int, 3)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			if err := q.acquire(ctx, "queued"); err != nil {
+				t.Errorf("err: %s", err)
+				return
+			}
+			order <- i
+			q.release()
+		}()
+
+		// Wait until the operation is queued, so the queue order is known.
+		for q.position(q.last()) != i {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	q.release()
+
+	for i := 1; i <= 3; i++ {
+		if got := <-order; got != i {
+			t.Fatalf("expected operation %d to acquire a slot, got %d", i, got)
+		}
+	}
+}
+
+func TestProvisioningQueue_concurrency(t *testing.T) {
+	q := newProvisioningQueue(2)
+	ctx := context.Background()
+
+	for _, name := range []string{"first", "second"} {
+		if err := q.acquire(ctx, name); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	if err := q.acquire(ctx, "third"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if len(q.waiting) != 0 {
+		t.Fatalf("expected cancelled operation to be removed from the queue")
+	}
+
+	q.release()
+	if err := q.acquire(context.Background(), "fourth"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestIsInProgressError(t *testing.T) {
+	cases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"resource in use": {
+			err:      awserr.New(servicecatalog.ErrCodeResourceInUseException, "in use", nil),
+			expected: true,
+		},
+		"control tower in progress": {
+			err:      awserr.New(servicecatalog.ErrCodeInvalidStateException, "AWS Control Tower cannot process the request: another operation is in progress", nil),
+			expected: true,
+		},
+		"invalid state": {
+			err:      awserr.New(servicecatalog.ErrCodeInvalidStateException, "provisioning artifact is not active", nil),
+			expected: false,
+		},
+		"other aws error mentioning in progress": {
+			err:      awserr.New(servicecatalog.ErrCodeInvalidParametersException, "parameter ProvisioningInProgress is invalid: value in progress is not allowed", nil),
+			expected: false,
+		},
+		"other aws error": {
+			err:      awserr.New(servicecatalog.ErrCodeInvalidParametersException, "invalid", nil),
+			expected: false,
+		},
+		"other error": {
+			err:      errors.New("in progress"),
+			expected: false,
+		},
+		"no error": {
+			err:      nil,
+			expected: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := isInProgressError(tc.err); got != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+// last returns the last queued operation.
+func (q *provisioningQueue) last() chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.waiting) == 0 {
+		return nil
+	}
+
+	return q.waiting[len(q.waiting)-1]
+}
@@ -1,36 +1,99 @@
 package mcaf
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"math/rand/v2"
+	"regexp"
+	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/organizations"
 	"github.com/aws/aws-sdk-go/service/servicecatalog"
+	"github.com/hashicorp/aws-sdk-go-base/tfawserr"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/time/rate"
+)
+
+// The strategies to move an account to another OU.
+const (
+	// ouMoveStrategyAccountFactory updates the provisioned product, which runs the Account Factory.
+	ouMoveStrategyAccountFactory = "account_factory"
+
+	// ouMoveStrategyOrganizations moves the account using Organizations, and only runs the
+	// Account Factory when the account needs to be re-registered with Control Tower.
+	ouMoveStrategyOrganizations = "organizations"
+
+	// ouMoveStrategyOrganizationsReregistered is recorded when the account was moved using
+	// Organizations and re-registered using the Account Factory.
+	ouMoveStrategyOrganizationsReregistered = "organizations_reregistered"
+)
+
+// The modes to delete an account with.
+const (
+	// deletionModeUnmanage terminates the provisioned product, which unmanages the account
+	// from Control Tower but leaves the account itself in the organization.
+	deletionModeUnmanage = "unmanage"
+
+	// deletionModeClose terminates the provisioned product and closes the account.
+	deletionModeClose = "close"
+
+	// deletionModeMoveToOU terminates the provisioned product and moves the account to
+	// the OU configured in deletion_organizational_unit_path.
+	deletionModeMoveToOU = "move_to_ou"
+
+	// deletionModeRetain only removes the account from the state.
+	deletionModeRetain = "retain"
 )
 
 func resourceAWSAccount() *schema.Resource {
 	return &schema.Resource{
-		Create: checkProvider("aws", resourceAWSAccountCreate),
-		Read:   checkProvider("aws", resourceAWSAccountRead),
-		Update: checkProvider("aws", resourceAWSAccountUpdate),
-		Delete: checkProvider("aws", resourceAWSAccountDelete),
+		CreateContext: checkProvider("aws", resourceAWSAccountCreate),
+		ReadContext:   checkProvider("aws", resourceAWSAccountRead),
+		UpdateContext: checkProvider("aws", resourceAWSAccountUpdate),
+		DeleteContext: checkProvider("aws", resourceAWSAccountDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceAWSAccountImport,
+		},
+
+		CustomizeDiff: customdiff.All(
+			resourceAWSAccountCustomizeDiffEmail,
+			customizeDiffTags,
+			resourceAWSAccountCustomizeDiffOrganizationalUnit,
+			resourceAWSAccountCustomizeDiffArtifact,
+			resourceAWSAccountCustomizeDiffDeletion,
+		),
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(60 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateAccountName,
 			},
 			"email": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateAccountEmail,
 			},
 			"sso": {
 				Type:     schema.TypeList,
@@ -39,18 +102,21 @@ func resourceAWSAccount() *schema.Resource {
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"firstname": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateSSOUserName,
 						},
 
 						"lastname": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateSSOUserName,
 						},
 
 						"email": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateAccountEmail,
 						},
 					},
 				},
@@ -60,11 +126,29 @@ func resourceAWSAccount() *schema.Resource {
 				Optional:      true,
 				Deprecated:    "This field is deprecated and will be removed in a future version. Please use organizational_unit_path instead.",
 				ConflictsWith: []string{"organizational_unit_path"},
+				ValidateFunc:  validateOrganizationalUnitPath,
 			},
 			"organizational_unit_path": {
-				Type:          schema.TypeString,
-				Optional:      true,
-				ConflictsWith: []string{"organizational_unit"},
+				Type:             schema.TypeString,
+				Optional:         true,
+				ConflictsWith:    []string{"organizational_unit"},
+				ValidateFunc:     validateOrganizationalUnitPath,
+				DiffSuppressFunc: suppressEquivalentOrganizationalUnitPath,
+			},
+			"validate_organizational_unit_path": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"ou_move_strategy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      ouMoveStrategyAccountFactory,
+				ValidateFunc: validation.StringInSlice([]string{ouMoveStrategyAccountFactory, ouMoveStrategyOrganizations}, false),
+			},
+			"last_ou_move_strategy": {
+				Type:     schema.TypeString,
+				Computed: true,
 			},
 			"provisioned_product_name": {
 				Type:     schema.TypeString,
@@ -72,6 +156,68 @@ func resourceAWSAccount() *schema.Resource {
 				Computed: true,
 				ForceNew: true,
 			},
+			"product_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"product_name"},
+			},
+			"product_name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"product_id"},
+			},
+			"provisioning_artifact_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"provisioning_artifact_name"},
+			},
+			"provisioning_artifact_name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"provisioning_artifact_id"},
+			},
+			"path_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"auto_update_artifact": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"provisioning_artifact_id", "provisioning_artifact_name"},
+			},
+			"deletion_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      deletionModeUnmanage,
+				ValidateFunc: validation.StringInSlice([]string{deletionModeUnmanage, deletionModeClose, deletionModeMoveToOU, deletionModeRetain}, false),
+			},
+			"deletion_organizational_unit_path": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentOrganizationalUnitPath,
+				ValidateFunc:     validateOrganizationalUnitPath,
+			},
+			"deletion_protection": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"tags": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"tags_all": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
 			"account_id": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -80,63 +226,19 @@ func resourceAWSAccount() *schema.Resource {
 	}
 }
 
-var accountMutex sync.Mutex
-
-func resourceAWSAccountCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAWSAccountCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	orgsconn := meta.(*Client).AWSClient.orgsconn
 	scconn := meta.(*Client).AWSClient.scconn
 
-	log.Printf("[DEBUG] Search the Account Factory product")
-	products, err := scconn.SearchProducts(&servicecatalog.SearchProductsInput{
-		Filters: map[string][]*string{"FullTextSearch": {aws.String("AWS Control Tower Account Factory")}},
-	})
-	if err != nil {
-		return fmt.Errorf("Error searching service catalog: %v", err)
-	}
-	if len(products.ProductViewSummaries) != 1 {
-		return fmt.Errorf("No Control Tower Account Factory found in your account. Please check your User and/or Role permissions and your Region settings.")
-	}
-
-	log.Printf("[DEBUG List all product artifacts to find the active artifact")
-	artifacts, err := scconn.ListProvisioningArtifacts(&servicecatalog.ListProvisioningArtifactsInput{
-		ProductId: products.ProductViewSummaries[0].ProductId,
-	})
-	if err != nil {
-		return fmt.Errorf("Error listing provisioning artifacts: %v", err)
-	}
-
-	// Try to find the active (which should be the latest) artifact.
-	artifactID := ""
-	for _, artifact := range artifacts.ProvisioningArtifactDetails {
-		if *artifact.Active {
-			artifactID = *artifact.Id
-			break
-		}
-	}
-	if artifactID == "" {
-		return fmt.Errorf("Could not find the provisioning artifact ID")
-	}
-
-	// Get organisation Root OU name and ID
-	roots, err := listRoots(orgsconn)
+	product, artifact, err := findAccountProduct(ctx, scconn, d)
 	if err != nil {
-		return err
-	}
-
-	// Support both organizational_unit and organizational_unit_path until deprecated organizational_unit field is removed
-	ou, ouOk := d.GetOk("organizational_unit")
-	ouPath, ouPathOk := d.GetOk("organizational_unit_path")
-	if !ouOk && !ouPathOk {
-		return errors.New("one of organizational_unit or organizational_unit_path must be configured")
-	}
-	if ouOk {
-		ouPath = ou
+		return diag.FromErr(err)
 	}
 
-	// Get child OU name and ID from provided path
-	managedOu, err := returnChildOu(orgsconn, ouPath.(string), aws.StringValue(roots[0].Id), aws.StringValue(roots[0].Name))
-	if err != nil {
-		return err
+	// Get child OU name and ID from the configured path
+	managedOu, diags := managedOrganizationalUnit(ctx, orgsconn, d)
+	if diags.HasError() {
+		return diags
 	}
 
 	// Get the name, ou and SSO details from the config.
@@ -151,9 +253,10 @@ func resourceAWSAccountCreate(d *schema.ResourceData, meta interface{}) error {
 
 	// Create a new parameters struct.
 	params := &servicecatalog.ProvisionProductInput{
-		ProductId:              products.ProductViewSummaries[0].ProductId,
+		ProductId:              product.ProductId,
 		ProvisionedProductName: aws.String(ppn),
-		ProvisioningArtifactId: aws.String(artifactID),
+		ProvisionToken:         aws.String(id.UniqueId()),
+		ProvisioningArtifactId: artifact.Id,
 		ProvisioningParameters: []*servicecatalog.ProvisioningParameter{
 			{
 				Key:   aws.String("AccountName"),
@@ -182,54 +285,123 @@ func resourceAWSAccountCreate(d *schema.ResourceData, meta interface{}) error {
 		},
 	}
 
+	if v, ok := d.GetOk("path_id"); ok {
+		params.PathId = aws.String(v.(string))
+	}
+
+	// Service Catalog only allows updating the tags of the provisioned product by running
+	// the Account Factory, so later changes are only applied to the account.
+	tags := d.Get("tags_all").(map[string]interface{})
+	for k, v := range tags {
+		params.Tags = append(params.Tags, &servicecatalog.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(v.(string)),
+		})
+	}
+
 	log.Printf("[DEBUG] Provision product parameters: %+v\n", params)
 
-	accountMutex.Lock()
-	defer accountMutex.Unlock()
+	queue := meta.(*Client).provisioningQueue
+	if err := queue.acquire(ctx, name); err != nil {
+		return diag.Errorf("Error waiting to provision account %s: %v", name, err)
+	}
+	defer queue.release()
 
 	log.Printf("[DEBUG] Provision account %s in organizational unit %s (%s)", name, aws.StringValue(managedOu.Name), aws.StringValue(managedOu.Id))
-	account, err := scconn.ProvisionProduct(params)
+	var account *servicecatalog.ProvisionProductOutput
+	err = retryWhenInProgress(ctx, d.Timeout(schema.TimeoutCreate), name, func() error {
+		var err error
+		account, err = scconn.ProvisionProductWithContext(ctx, params)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("Error provisioning account %s: %v", name, err)
+		return diag.Errorf("Error provisioning account %s: %v", name, err)
 	}
 
 	// Set the ID so we can cleanup the provisioned account in case of a failure.
 	d.SetId(*account.RecordDetail.ProvisionedProductId)
+	setAccountProduct(d, product, artifact)
 
 	// Wait for the provisioning to finish.
-	err = waitForProvisioning(name, account.RecordDetail.RecordId, meta)
+	err = waitForProvisioning(ctx, name, account.RecordDetail.RecordId, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+
+	// Failing to tag the account must not taint it, the tags are applied on the next apply instead.
+	if len(tags) > 0 {
+		if err := tagProvisionedAccount(ctx, orgsconn, scconn, d.Id(), tags); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Error tagging account %s", name),
+				Detail:   fmt.Sprintf("%v\n\nThe tags will be applied on the next apply.", err),
+			})
+		}
+	}
+
+	return append(diags, resourceAWSAccountRead(ctx, d, meta)...)
+}
+
+// tagProvisionedAccount tags the account vended by the provisioned product.
+func tagProvisionedAccount(ctx context.Context, orgsconn organizationsAPI, scconn serviceCatalogAPI, ppID string, tags map[string]interface{}) error {
+	outputs, err := provisionedProductOutputs(ctx, scconn, ppID)
+	if err != nil {
+		return fmt.Errorf("error reading outputs of provisioned account %s: %v", ppID, err)
 	}
 
-	return resourceAWSAccountRead(d, meta)
+	return updateOrganizationsTags(ctx, orgsconn, outputs["AccountId"], nil, tags)
 }
 
-func resourceAWSAccountRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAWSAccountRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfconn := meta.(*Client).AWSClient.cfconn
+	orgsconn := meta.(*Client).AWSClient.orgsconn
 	scconn := meta.(*Client).AWSClient.scconn
 
 	// Get the name from the config.
 	name := d.Get("name").(string)
 
 	log.Printf("[DEBUG] Read configuration of provisioned account %s: %s", name, d.Id())
-	account, err := scconn.DescribeProvisionedProduct(&servicecatalog.DescribeProvisionedProductInput{
+	account, err := scconn.DescribeProvisionedProductWithContext(ctx, &servicecatalog.DescribeProvisionedProductInput{
 		Id: aws.String(d.Id()),
 	})
+	if tfawserr.ErrCodeEquals(err, servicecatalog.ErrCodeResourceNotFoundException) {
+		log.Printf("[WARN] Provisioned account %s (%s) not found, removing from state", name, d.Id())
+		d.SetId("")
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("Error reading configuration of provisioned account %s: %v", name, err)
+		return diag.Errorf("Error reading configuration of provisioned account %s: %v", name, err)
 	}
 
 	record := &servicecatalog.DescribeRecordInput{
 		Id: account.ProvisionedProductDetail.LastRecordId,
 	}
 
-	status, err := scconn.DescribeRecord(record)
+	status, err := scconn.DescribeRecordWithContext(ctx, record)
 	if err != nil {
-		return fmt.Errorf("Error reading configuration of provisioned account %s: %v", name, err)
+		return diag.Errorf("Error reading configuration of provisioned account %s: %v", name, err)
 	}
 
 	// Update the config.
 	d.Set("provisioned_product_name", *account.ProvisionedProductDetail.Name)
+	d.Set("path_id", status.RecordDetail.PathId)
+
+	// The names of the product and artifact are only known when they were looked up
+	// by the provider, so they are cleared when the product or artifact was changed.
+	if productID := aws.StringValue(account.ProvisionedProductDetail.ProductId); productID != d.Get("product_id").(string) {
+		d.Set("product_id", productID)
+		d.Set("product_name", "")
+	}
+	if artifactID := aws.StringValue(account.ProvisionedProductDetail.ProvisioningArtifactId); artifactID != d.Get("provisioning_artifact_id").(string) {
+		d.Set("provisioning_artifact_id", artifactID)
+		d.Set("provisioning_artifact_name", "")
+	}
+
+	// Without auto_update_artifact an outdated artifact is reported, but not updated.
+	var diags diag.Diagnostics
+	if !d.Get("auto_update_artifact").(bool) {
+		diags = append(diags, outdatedArtifactWarning(ctx, scconn, d)...)
+	}
 	for _, output := range status.RecordOutputs {
 		switch *output.OutputKey {
 		case "AccountName":
@@ -241,42 +413,182 @@ func resourceAWSAccountRead(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
-	return nil
-}
+	// Read the parameters used on the last provisioning to detect SSO drift. Without
+	// permission to read them, the sso details are kept as they are.
+	parameters, err := provisionedProductParameters(ctx, scconn, cfconn, d.Id())
+	if err != nil {
+		// CloudFormation reports a missing permission as AccessDenied, Service Catalog as AccessDeniedException.
+		if !tfawserr.ErrCodeEquals(err, "AccessDenied", "AccessDeniedException") {
+			return diag.FromErr(err)
+		}
+		log.Printf("[WARN] Unable to read provisioning parameters of provisioned account %s, skipping sso drift detection: %v", name, err)
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Warning,
+			Summary:       "Unable to detect drift of the sso details",
+			Detail:        fmt.Sprintf("Reading the provisioning parameters of account %s was denied: %v", name, err),
+			AttributePath: cty.GetAttrPath("sso"),
+		})
+	}
 
-func resourceAWSAccountUpdate(d *schema.ResourceData, meta interface{}) error {
-	orgsconn := meta.(*Client).AWSClient.orgsconn
-	scconn := meta.(*Client).AWSClient.scconn
+	// Products other than the Account Factory may not have the SSO parameters.
+	_, hasFirstName := parameters["SSOUserFirstName"]
+	_, hasLastName := parameters["SSOUserLastName"]
+	_, hasEmail := parameters["SSOUserEmail"]
+	if hasFirstName && hasLastName && hasEmail {
+		d.Set("sso", []interface{}{
+			map[string]interface{}{
+				"firstname": parameters["SSOUserFirstName"],
+				"lastname":  parameters["SSOUserLastName"],
+				"email":     parameters["SSOUserEmail"],
+			},
+		})
+	}
 
-	// Get organisation Root OU name and ID
-	roots, err := listRoots(orgsconn)
+	accountID := d.Get("account_id").(string)
+	if accountID == "" {
+		log.Printf("[WARN] No account ID found for provisioned account %s, unable to read organizational unit", name)
+		return diags
+	}
+
+	// Read the OU the account is currently placed in to detect moved accounts.
+	ouPath, err := accountOrganizationalUnitPath(ctx, orgsconn, accountID)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
 	// Support both organizational_unit and organizational_unit_path until deprecated organizational_unit field is removed
-	ou, ouOk := d.GetOk("organizational_unit")
-	ouPath, ouPathOk := d.GetOk("organizational_unit_path")
-	if !ouOk && !ouPathOk {
-		return errors.New("one of organizational_unit or organizational_unit_path must be configured")
+	ouKey := "organizational_unit_path"
+	if _, ok := d.GetOk("organizational_unit"); ok {
+		ouKey = "organizational_unit"
 	}
-	if ouOk {
-		ouPath = ou
+
+	// Only update the path if the account was moved, to preserve the configured notation.
+	if normalizeOrganizationalUnitPath(d.Get(ouKey).(string)) != normalizeOrganizationalUnitPath(ouPath) {
+		d.Set(ouKey, ouPath)
 	}
 
-	// Get child OU name and ID from provided path
-	managedOu, err := returnChildOu(orgsconn, ouPath.(string), aws.StringValue(roots[0].Id), aws.StringValue(roots[0].Name))
+	tags, err := listTags(ctx, orgsconn, accountID)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("tags", tagsWithoutDefaults(tags, d.Get("tags").(map[string]interface{}), meta.(*Client).AWSClient.defaultTags)); err != nil {
+		return diag.Errorf("Error setting tags: %s", err)
+	}
+	if err := d.Set("tags_all", tags); err != nil {
+		return diag.Errorf("Error setting tags_all: %s", err)
+	}
+
+	return diags
+}
+
+// outdatedArtifactWarning returns a warning when the account is provisioned using
+// another provisioning artifact than the active artifact of its product, as a new
+// artifact becomes active whenever Control Tower is updated.
+func outdatedArtifactWarning(ctx context.Context, conn serviceCatalogAPI, d *schema.ResourceData) diag.Diagnostics {
+	productID := d.Get("product_id").(string)
+	artifactID := d.Get("provisioning_artifact_id").(string)
+	if productID == "" || artifactID == "" {
+		return nil
+	}
+
+	active, err := findProvisioningArtifact(ctx, conn, productID, "", "")
+	if err != nil {
+		log.Printf("[WARN] Unable to check if provisioned account %s uses the active artifact: %v", d.Get("name").(string), err)
+		return nil
+	}
+
+	if aws.StringValue(active.Id) == artifactID {
+		return nil
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "Provisioned account uses an outdated artifact",
+		Detail: fmt.Sprintf("Account %s is provisioned using artifact %s of product %s, while %s (%s) is the active artifact. "+
+			"Set auto_update_artifact to update the account to the active artifact.",
+			d.Get("name").(string), artifactID, productID, aws.StringValue(active.Id), aws.StringValue(active.Name)),
+		AttributePath: cty.GetAttrPath("provisioning_artifact_id"),
+	}}
+}
+
+func resourceAWSAccountUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	orgsconn := meta.(*Client).AWSClient.orgsconn
+	scconn := meta.(*Client).AWSClient.scconn
+
+	// Tags are updated in place, without running the Account Factory.
+	if d.HasChange("tags_all") {
+		o, n := d.GetChange("tags_all")
+		if err := updateOrganizationsTags(ctx, orgsconn, d.Get("account_id").(string), o.(map[string]interface{}), n.(map[string]interface{})); err != nil {
+			return diag.Errorf("Error updating tags of account %s: %v", d.Get("name").(string), err)
+		}
+	}
+
+	// Changing the OU move strategy by itself doesn't require an update.
+	if !d.HasChanges("sso", "organizational_unit", "organizational_unit_path", "path_id",
+		"product_id", "product_name", "provisioning_artifact_id", "provisioning_artifact_name") {
+		return resourceAWSAccountRead(ctx, d, meta)
+	}
+
+	// Look up the product and artifact when they changed, otherwise the ones used before are kept.
+	var product *servicecatalog.ProductViewSummary
+	var artifact *servicecatalog.ProvisioningArtifactDetail
+	var productChanged bool
+
+	if d.HasChanges("product_id", "product_name", "provisioning_artifact_id", "provisioning_artifact_name") {
+		var err error
+		product, artifact, err = findAccountProduct(ctx, scconn, d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		oldProductID, _ := d.GetChange("product_id")
+		oldArtifactID, _ := d.GetChange("provisioning_artifact_id")
+		productChanged = aws.StringValue(product.ProductId) != oldProductID.(string) ||
+			aws.StringValue(artifact.Id) != oldArtifactID.(string)
+	}
+	productChanged = productChanged || d.HasChange("path_id")
+
+	// Only the names of the product or artifact changed, e.g. when switching from an ID to a name.
+	if !productChanged && !d.HasChanges("sso", "organizational_unit", "organizational_unit_path") {
+		setAccountProduct(d, product, artifact)
+		return resourceAWSAccountRead(ctx, d, meta)
+	}
+
+	// Get child OU name and ID from the configured path
+	managedOu, diags := managedOrganizationalUnit(ctx, orgsconn, d)
+	if diags.HasError() {
+		return diags
 	}
 
 	// Get the name, ou and SSO details from the config.
 	name := d.Get("name").(string)
 	sso := d.Get("sso").([]interface{})[0].(map[string]interface{})
 
+	var moveStrategy string
+	if d.HasChanges("organizational_unit", "organizational_unit_path") {
+		moveStrategy = ouMoveStrategyAccountFactory
+
+		if d.Get("ou_move_strategy").(string) == ouMoveStrategyOrganizations {
+			reregister, err := moveAccountWithOrganizations(ctx, d, meta, managedOu)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+
+			if !reregister && !productChanged {
+				d.Set("last_ou_move_strategy", ouMoveStrategyOrganizations)
+				setAccountProduct(d, product, artifact)
+				return resourceAWSAccountRead(ctx, d, meta)
+			}
+
+			moveStrategy = ouMoveStrategyOrganizationsReregistered
+		}
+	}
+
 	// Create a new parameters struct.
 	params := &servicecatalog.UpdateProvisionedProductInput{
 		ProvisionedProductId: aws.String(d.Id()),
+		UpdateToken:          aws.String(id.UniqueId()),
 		ProvisioningParameters: []*servicecatalog.UpdateProvisioningParameter{
 			{
 				Key:   aws.String("AccountName"),
@@ -305,114 +617,1020 @@ func resourceAWSAccountUpdate(d *schema.ResourceData, meta interface{}) error {
 		},
 	}
 
-	accountMutex.Lock()
-	defer accountMutex.Unlock()
+	// Without a changed product or artifact, the ones used before are kept.
+	if product != nil {
+		params.ProductId = product.ProductId
+		params.ProvisioningArtifactId = artifact.Id
+	} else {
+		if v, ok := d.GetOk("product_id"); ok {
+			params.ProductId = aws.String(v.(string))
+		}
+		if v, ok := d.GetOk("provisioning_artifact_id"); ok {
+			params.ProvisioningArtifactId = aws.String(v.(string))
+		}
+	}
+	if v, ok := d.GetOk("path_id"); ok {
+		params.PathId = aws.String(v.(string))
+	}
+
+	queue := meta.(*Client).provisioningQueue
+	if err := queue.acquire(ctx, name); err != nil {
+		return diag.Errorf("Error waiting to update provisioned account %s: %v", name, err)
+	}
+	defer queue.release()
 
 	log.Printf("[DEBUG] Update provisioned account %s: %s", name, d.Id())
-	account, err := scconn.UpdateProvisionedProduct(params)
+	var account *servicecatalog.UpdateProvisionedProductOutput
+	err := retryWhenInProgress(ctx, d.Timeout(schema.TimeoutUpdate), name, func() error {
+		var err error
+		account, err = scconn.UpdateProvisionedProductWithContext(ctx, params)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("Error updating provisioned account %s: %v", name, err)
+		return diag.Errorf("Error updating provisioned account %s: %v", name, err)
 	}
 
 	// Wait for the provisioning to finish.
-	err = waitForProvisioning(name, account.RecordDetail.RecordId, meta)
+	err = waitForProvisioning(ctx, name, account.RecordDetail.RecordId, meta)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+
+	if moveStrategy != "" {
+		d.Set("last_ou_move_strategy", moveStrategy)
 	}
+	setAccountProduct(d, product, artifact)
 
-	return resourceAWSAccountRead(d, meta)
+	return resourceAWSAccountRead(ctx, d, meta)
 }
 
-func resourceAWSAccountDelete(d *schema.ResourceData, meta interface{}) error {
-	scconn := meta.(*Client).AWSClient.scconn
+// resourceAWSAccountCustomizeDiffEmail checks during plan that the email address of a
+// new account is not already used by another account in the organization, as the
+// Account Factory only fails on this after a long run.
+func resourceAWSAccountCustomizeDiffEmail(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if (d.Id() != "" && !d.HasChange("email")) || !d.NewValueKnown("email") {
+		return nil
+	}
 
-	// Get the name from the config.
-	name := d.Get("name").(string)
+	client := meta.(*Client).AWSClient
+	if client == nil {
+		return fmt.Errorf("Missing AWS provider configuration")
+	}
 
-	accountMutex.Lock()
-	defer accountMutex.Unlock()
+	email := d.Get("email").(string)
 
-	log.Printf("[DEBUG] Delete provisioned account %s: %s", name, d.Id())
-	account, err := scconn.TerminateProvisionedProduct(&servicecatalog.TerminateProvisionedProductInput{
-		ProvisionedProductId: aws.String(d.Id()),
+	var existing *organizations.Account
+	log.Printf("[DEBUG] Check if email %s is used by another account", email)
+	err := client.orgsconn.ListAccountsPagesWithContext(ctx, &organizations.ListAccountsInput{}, func(page *organizations.ListAccountsOutput, lastPage bool) bool {
+		for _, account := range page.Accounts {
+			if strings.EqualFold(aws.StringValue(account.Email), email) {
+				existing = account
+				return false
+			}
+		}
+		return !lastPage
 	})
 	if err != nil {
-		return fmt.Errorf("Error deleting provisioned account %s: %v", name, err)
+		return fmt.Errorf("Error listing accounts: %v", err)
 	}
 
-	// Wait for the provisioning to finish.
-	return waitForProvisioning(name, account.RecordDetail.RecordId, meta)
+	if existing != nil && aws.StringValue(existing.Id) != d.Get("account_id").(string) {
+		return fmt.Errorf("Email %s is already used by account %s (%s)", email, aws.StringValue(existing.Name), aws.StringValue(existing.Id))
+	}
+
+	return nil
 }
 
-// returnChildOu returns the ID of the child OU with the given path.
-func returnChildOu(conn *organizations.Organizations, path, ouID, ouName string) (*organizations.OrganizationalUnit, error) {
-	ou := &organizations.OrganizationalUnit{}
+// resourceAWSAccountCustomizeDiffOrganizationalUnit resolves new and changed OU paths
+// during plan, so a missing OU is reported before the Account Factory runs.
+func resourceAWSAccountCustomizeDiffOrganizationalUnit(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("validate_organizational_unit_path").(bool) {
+		return nil
+	}
 
-	for _, v := range strings.Split(path, "/") {
-		if strings.EqualFold(v, "Root") {
-			continue
-		}
+	// Support both organizational_unit and organizational_unit_path until deprecated organizational_unit field is removed
+	ouKey := "organizational_unit_path"
+	if _, ok := d.GetOk("organizational_unit"); ok {
+		ouKey = "organizational_unit"
+	}
 
-		input := &organizations.ListOrganizationalUnitsForParentInput{
-			ParentId: aws.String(ouID),
-		}
+	// Paths that are only known during apply can't be resolved yet.
+	if (d.Id() != "" && !d.HasChange(ouKey)) || !d.NewValueKnown(ouKey) {
+		return nil
+	}
 
-		var childOuID, childOuName string
-		log.Printf("[DEBUG] Listing OUs under parent: %s (%s)", ouName, ouID)
-		err := conn.ListOrganizationalUnitsForParentPages(input, func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
-			for _, childOu := range page.OrganizationalUnits {
-				if *childOu.Name == v {
-					childOuID = *childOu.Id
-					childOuName = *childOu.Name
-					ou = childOu
-					return false
-				}
-			}
-			return true
-		})
-		if err != nil {
-			return nil, fmt.Errorf("error listing organizational units for parent %s (%s): %v", ouName, ouID, err)
-		}
+	path := d.Get(ouKey).(string)
+	if path == "" {
+		return nil
+	}
 
-		if childOuID == "" {
-			return nil, fmt.Errorf("organizational unit %s not found in parent %s (%s)", ou, ouName, ouID)
-		}
+	client := meta.(*Client).AWSClient
+	if client == nil {
+		return fmt.Errorf("Missing AWS provider configuration")
+	}
 
-		ouID = childOuID
-		ouName = childOuName
+	roots, err := listRoots(ctx, client.orgsconn)
+	if err != nil {
+		return err
 	}
 
-	return ou, nil
+	if _, err := findOrganizationalUnitByPath(ctx, client.orgsconn, path, aws.StringValue(roots[0].Id), aws.StringValue(roots[0].Name), false); err != nil {
+		return fmt.Errorf("Invalid %s %q: %v", ouKey, path, err)
+	}
+
+	return nil
 }
 
-// waitForProvisioning waits until the provisioning finished.
-func waitForProvisioning(name string, recordID *string, meta interface{}) error {
-	scconn := meta.(*Client).AWSClient.scconn
+// resourceAWSAccountCustomizeDiffArtifact plans an update of the provisioned account to the
+// active provisioning artifact of its product when auto_update_artifact is enabled, as
+// a new artifact becomes active whenever Control Tower is updated.
+func resourceAWSAccountCustomizeDiffArtifact(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.Get("auto_update_artifact").(bool) {
+		return nil
+	}
 
-	record := &servicecatalog.DescribeRecordInput{
-		Id: recordID,
+	// A changed product is looked up, together with its active artifact, on apply.
+	if d.HasChanges("product_id", "product_name") {
+		return nil
 	}
 
-	for {
-		// Get the provisioning status.
-		status, err := scconn.DescribeRecord(record)
-		if err != nil {
-			return fmt.Errorf("Error reading provisioning status of account %s: %v", name, err)
-		}
+	productID := d.Get("product_id").(string)
+	if productID == "" {
+		return nil
+	}
 
-		// If the provisioning succeeded we are done.
-		if *status.RecordDetail.Status == servicecatalog.RecordStatusSucceeded {
-			break
-		}
+	client := meta.(*Client).AWSClient
+	if client == nil {
+		return fmt.Errorf("Missing AWS provider configuration")
+	}
 
-		// If the provisioning failed we try to cleanup the tainted account.
-		if *status.RecordDetail.Status == servicecatalog.RecordStatusFailed {
-			return fmt.Errorf("Provisioning account %s failed: %s", name, *status.RecordDetail.RecordErrors[0].Description)
+	artifact, err := findProvisioningArtifact(ctx, client.scconn, productID, "", "")
+	if err != nil {
+		return err
+	}
+
+	if artifactID := aws.StringValue(artifact.Id); artifactID != d.Get("provisioning_artifact_id").(string) {
+		log.Printf("[INFO] Provisioned account %s uses artifact %s, updating it to the active artifact %s",
+			d.Get("name").(string), d.Get("provisioning_artifact_id").(string), artifactID)
+
+		if err := d.SetNew("provisioning_artifact_id", artifactID); err != nil {
+			return err
 		}
+		if err := d.SetNew("provisioning_artifact_name", aws.StringValue(artifact.Name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resourceAWSAccountCustomizeDiffDeletion checks during plan that the OU to move the
+// account to on deletion is configured, and exists, when deletion_mode is move_to_ou.
+func resourceAWSAccountCustomizeDiffDeletion(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get("deletion_mode").(string) != deletionModeMoveToOU || !d.NewValueKnown("deletion_organizational_unit_path") {
+		return nil
+	}
+
+	path := d.Get("deletion_organizational_unit_path").(string)
+	if path == "" {
+		return fmt.Errorf("deletion_organizational_unit_path must be configured when deletion_mode is %s", deletionModeMoveToOU)
+	}
+
+	if !d.Get("validate_organizational_unit_path").(bool) || (d.Id() != "" && !d.HasChanges("deletion_mode", "deletion_organizational_unit_path")) {
+		return nil
+	}
+
+	client := meta.(*Client).AWSClient
+	if client == nil {
+		return fmt.Errorf("Missing AWS provider configuration")
+	}
+
+	roots, err := listRoots(ctx, client.orgsconn)
+	if err != nil {
+		return err
+	}
 
-		// Wait 5 seconds before checking the status again.
-		time.Sleep(5 * time.Second)
+	if _, err := findOrganizationalUnitByPath(ctx, client.orgsconn, path, aws.StringValue(roots[0].Id), aws.StringValue(roots[0].Name), false); err != nil {
+		return fmt.Errorf("Invalid deletion_organizational_unit_path %q: %v", path, err)
 	}
 
 	return nil
 }
+
+// setAccountProduct records the product and provisioning artifact used to provision
+// the account, if they were looked up.
+func setAccountProduct(d *schema.ResourceData, product *servicecatalog.ProductViewSummary, artifact *servicecatalog.ProvisioningArtifactDetail) {
+	if product == nil {
+		return
+	}
+
+	d.Set("product_id", product.ProductId)
+	d.Set("product_name", product.Name)
+	d.Set("provisioning_artifact_id", artifact.Id)
+	d.Set("provisioning_artifact_name", artifact.Name)
+}
+
+// moveAccountWithOrganizations moves the account to the OU using Organizations, and
+// returns whether the account still needs to be re-registered with Control Tower by
+// running the Account Factory. That is the case when the SSO details changed as well,
+// or when the provisioned product is not available (e.g. tainted by an earlier failure).
+func moveAccountWithOrganizations(ctx context.Context, d *schema.ResourceData, meta interface{}, ou *organizations.OrganizationalUnit) (bool, error) {
+	orgsconn := meta.(*Client).AWSClient.orgsconn
+	scconn := meta.(*Client).AWSClient.scconn
+
+	name := d.Get("name").(string)
+	accountID := d.Get("account_id").(string)
+
+	if err := moveAccount(ctx, orgsconn, name, accountID, ou); err != nil {
+		return false, err
+	}
+
+	if d.HasChange("sso") {
+		return true, nil
+	}
+
+	pp, err := scconn.DescribeProvisionedProductWithContext(ctx, &servicecatalog.DescribeProvisionedProductInput{
+		Id: aws.String(d.Id()),
+	})
+	if err != nil {
+		return false, fmt.Errorf("Error reading provisioned account %s: %v", name, err)
+	}
+
+	status := aws.StringValue(pp.ProvisionedProductDetail.Status)
+	if status != servicecatalog.ProvisionedProductStatusAvailable {
+		log.Printf("[INFO] Provisioned account %s is %s, re-registering it using the Account Factory", name, status)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// moveAccount moves the account to the OU using Organizations, unless it is already in it.
+func moveAccount(ctx context.Context, conn organizationsAPI, name, accountID string, ou *organizations.OrganizationalUnit) error {
+	parents, err := conn.ListParentsWithContext(ctx, &organizations.ListParentsInput{
+		ChildId: aws.String(accountID),
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing parents of account %s: %v", name, err)
+	}
+	if len(parents.Parents) == 0 {
+		return fmt.Errorf("Error moving account %s: no parent found", name)
+	}
+
+	if sourceID := aws.StringValue(parents.Parents[0].Id); sourceID != aws.StringValue(ou.Id) {
+		log.Printf("[DEBUG] Move account %s from %s to organizational unit %s (%s)", name, sourceID, aws.StringValue(ou.Name), aws.StringValue(ou.Id))
+		_, err = conn.MoveAccountWithContext(ctx, &organizations.MoveAccountInput{
+			AccountId:           aws.String(accountID),
+			DestinationParentId: ou.Id,
+			SourceParentId:      aws.String(sourceID),
+		})
+		if err != nil {
+			return fmt.Errorf("Error moving account %s to organizational unit %s: %v", name, aws.StringValue(ou.Name), err)
+		}
+	}
+
+	return nil
+}
+
+func resourceAWSAccountDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	orgsconn := meta.(*Client).AWSClient.orgsconn
+
+	// Get the name from the config.
+	name := d.Get("name").(string)
+	accountID := d.Get("account_id").(string)
+	mode := d.Get("deletion_mode").(string)
+
+	if d.Get("deletion_protection").(bool) {
+		return diag.Errorf("Cannot delete account %s: deletion_protection is enabled, set it to false and apply before deleting the account", name)
+	}
+
+	if mode == deletionModeRetain {
+		log.Printf("[INFO] Retaining account %s (%s), only removing it from the state", name, accountID)
+		return nil
+	}
+
+	if (mode == deletionModeClose || mode == deletionModeMoveToOU) && accountID == "" {
+		return diag.Errorf("Cannot delete account %s with deletion_mode %s: the account ID is unknown", name, mode)
+	}
+
+	// Resolve the OU before terminating, so a missing OU doesn't leave an unmanaged account behind.
+	var deletionOU *organizations.OrganizationalUnit
+	if mode == deletionModeMoveToOU {
+		path := d.Get("deletion_organizational_unit_path").(string)
+		if path == "" {
+			return diag.Errorf("deletion_organizational_unit_path must be configured when deletion_mode is %s", deletionModeMoveToOU)
+		}
+
+		roots, err := listRoots(ctx, orgsconn)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		deletionOU, err = returnChildOu(ctx, orgsconn, path, aws.StringValue(roots[0].Id), aws.StringValue(roots[0].Name))
+		if err != nil {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Invalid organizational unit path",
+				Detail:        err.Error(),
+				AttributePath: cty.GetAttrPath("deletion_organizational_unit_path"),
+			}}
+		}
+	}
+
+	if err := terminateProvisionedAccount(ctx, d, meta); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Closing or moving the account is done without holding a provisioning slot, as
+	// waiting for the account to be closed doesn't involve the Account Factory.
+	switch mode {
+	case deletionModeClose:
+		if err := closeAccount(ctx, orgsconn, d.Timeout(schema.TimeoutDelete), name, accountID); err != nil {
+			return diag.FromErr(err)
+		}
+	case deletionModeMoveToOU:
+		if err := moveAccount(ctx, orgsconn, name, accountID, deletionOU); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return nil
+}
+
+// terminateProvisionedAccount terminates the provisioned product of the account and waits
+// for the termination to finish, holding a provisioning slot while doing so.
+func terminateProvisionedAccount(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	scconn := meta.(*Client).AWSClient.scconn
+
+	name := d.Get("name").(string)
+
+	queue := meta.(*Client).provisioningQueue
+	if err := queue.acquire(ctx, name); err != nil {
+		return fmt.Errorf("Error waiting to delete provisioned account %s: %v", name, err)
+	}
+	defer queue.release()
+
+	log.Printf("[DEBUG] Delete provisioned account %s: %s", name, d.Id())
+	input := &servicecatalog.TerminateProvisionedProductInput{
+		ProvisionedProductId: aws.String(d.Id()),
+		TerminateToken:       aws.String(id.UniqueId()),
+	}
+
+	var account *servicecatalog.TerminateProvisionedProductOutput
+	err := retryWhenInProgress(ctx, d.Timeout(schema.TimeoutDelete), name, func() error {
+		var err error
+		account, err = scconn.TerminateProvisionedProductWithContext(ctx, input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Error deleting provisioned account %s: %v", name, err)
+	}
+
+	// Wait for the provisioning to finish.
+	return waitForProvisioning(ctx, name, account.RecordDetail.RecordId, meta)
+}
+
+// accountClosePollInterval is the delay between polls of the status of a closing account.
+var accountClosePollInterval = 30 * time.Second
+
+// closeAccount closes the account and waits until it is suspended.
+func closeAccount(ctx context.Context, conn organizationsAPI, timeout time.Duration, name, accountID string) error {
+	log.Printf("[DEBUG] Close account %s (%s)", name, accountID)
+	_, err := conn.CloseAccountWithContext(ctx, &organizations.CloseAccountInput{
+		AccountId: aws.String(accountID),
+	})
+	if err != nil && !tfawserr.ErrCodeEquals(err, organizations.ErrCodeAccountAlreadyClosedException) {
+		return fmt.Errorf("Error closing account %s: %v", name, err)
+	}
+
+	wait := &retry.StateChangeConf{
+		Pending:      []string{organizations.AccountStatusActive, organizations.AccountStatusPendingClosure},
+		Target:       []string{organizations.AccountStatusSuspended},
+		Timeout:      timeout,
+		PollInterval: accountClosePollInterval,
+		Refresh: func() (interface{}, string, error) {
+			output, err := conn.DescribeAccountWithContext(ctx, &organizations.DescribeAccountInput{
+				AccountId: aws.String(accountID),
+			})
+			if err != nil {
+				return nil, "", err
+			}
+			return output.Account, aws.StringValue(output.Account.Status), nil
+		},
+	}
+
+	if _, err := wait.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("Error waiting for account %s to be closed: %v", name, err)
+	}
+
+	return nil
+}
+
+func resourceAWSAccountImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	scconn := meta.(*Client).AWSClient.scconn
+
+	// The import ID can be a provisioned product ID, an account ID or an account email.
+	importID := d.Id()
+	if strings.HasPrefix(importID, "pp-") {
+		setAccountDefaults(d)
+		return []*schema.ResourceData{d}, nil
+	}
+
+	if !accountIDRegexp.MatchString(importID) && !strings.Contains(importID, "@") {
+		return nil, fmt.Errorf("Invalid import ID %q: expected a provisioned product ID, account ID or account email", importID)
+	}
+
+	ppID, err := findProvisionedAccount(ctx, scconn, meta.(*Client).AWSClient.scLimiter, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] Import provisioned account %s: %s", importID, ppID)
+	d.SetId(ppID)
+	setAccountDefaults(d)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// setAccountDefaults sets the arguments that only exist in Terraform to their defaults,
+// so an imported account doesn't show a diff for them.
+func setAccountDefaults(d *schema.ResourceData) {
+	for k, v := range resourceAWSAccount().Schema {
+		if v.Default != nil {
+			d.Set(k, v.Default)
+		}
+	}
+}
+
+var accountIDRegexp = regexp.MustCompile(`^\d{12}$`)
+
+// The constraints of the Account Factory provisioning parameters.
+var (
+	accountEmailRegexp = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+	accountNameRegexp  = regexp.MustCompile(`^[\x20-\x7E]+$`)
+
+	validateAccountEmail = validation.All(
+		validation.StringLenBetween(6, 64),
+		validation.StringMatch(accountEmailRegexp, "must be a valid email address"),
+	)
+
+	validateAccountName = validation.All(
+		validation.StringLenBetween(1, 50),
+		validation.StringMatch(accountNameRegexp, "must only contain printable ASCII characters"),
+		validation.StringIsNotWhiteSpace,
+	)
+
+	validateSSOUserName = validation.All(
+		validation.StringLenBetween(1, 50),
+		validation.StringIsNotWhiteSpace,
+	)
+)
+
+// findAccountFactoryProduct returns the Control Tower Account Factory product.
+func findAccountFactoryProduct(ctx context.Context, conn serviceCatalogAPI) (*servicecatalog.ProductViewSummary, error) {
+	log.Printf("[DEBUG] Search the Account Factory product")
+	products, err := conn.SearchProductsWithContext(ctx, &servicecatalog.SearchProductsInput{
+		Filters: map[string][]*string{"FullTextSearch": {aws.String("AWS Control Tower Account Factory")}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error searching service catalog: %v", err)
+	}
+	if len(products.ProductViewSummaries) != 1 {
+		return nil, fmt.Errorf("No Control Tower Account Factory found in your account. Please check your User and/or Role permissions and your Region settings.")
+	}
+
+	return products.ProductViewSummaries[0], nil
+}
+
+// findAccountProduct returns the product and provisioning artifact to provision the
+// account with. When no product is configured the Control Tower Account Factory is
+// used, and when no artifact is configured the active artifact of the product. On
+// updates only the arguments that changed are looked up, as the others still contain
+// the product and artifact used before.
+func findAccountProduct(ctx context.Context, conn serviceCatalogAPI, d *schema.ResourceData) (*servicecatalog.ProductViewSummary, *servicecatalog.ProvisioningArtifactDetail, error) {
+	var productID, productName string
+	switch {
+	case d.HasChange("product_id"):
+		productID = d.Get("product_id").(string)
+	case d.HasChange("product_name"):
+		productName = d.Get("product_name").(string)
+	default:
+		productID = d.Get("product_id").(string)
+	}
+
+	product, err := findProduct(ctx, conn, productID, productName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oldProductID, _ := d.GetChange("product_id")
+
+	var artifactID, artifactName string
+	switch {
+	case d.HasChange("provisioning_artifact_id"):
+		artifactID = d.Get("provisioning_artifact_id").(string)
+	case d.HasChange("provisioning_artifact_name"):
+		artifactName = d.Get("provisioning_artifact_name").(string)
+	case aws.StringValue(product.ProductId) == oldProductID.(string):
+		artifactID = d.Get("provisioning_artifact_id").(string)
+	}
+
+	artifact, err := findProvisioningArtifact(ctx, conn, aws.StringValue(product.ProductId), artifactID, artifactName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return product, artifact, nil
+}
+
+// findProduct returns the product with the given ID or name, or the Control Tower
+// Account Factory if neither is given.
+func findProduct(ctx context.Context, conn serviceCatalogAPI, productID, productName string) (*servicecatalog.ProductViewSummary, error) {
+	if productID == "" && productName == "" {
+		return findAccountFactoryProduct(ctx, conn)
+	}
+
+	input := &servicecatalog.DescribeProductInput{}
+	if productID != "" {
+		input.Id = aws.String(productID)
+	} else {
+		input.Name = aws.String(productName)
+	}
+
+	log.Printf("[DEBUG] Describe product %s%s", productID, productName)
+	product, err := conn.DescribeProductWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading product %s%s: %v", productID, productName, err)
+	}
+
+	return product.ProductViewSummary, nil
+}
+
+// findProvisioningArtifact returns the provisioning artifact of the product with the
+// given ID or name, or the active (which should be the latest) artifact if neither is given.
+func findProvisioningArtifact(ctx context.Context, conn serviceCatalogAPI, productID, artifactID, artifactName string) (*servicecatalog.ProvisioningArtifactDetail, error) {
+	log.Printf("[DEBUG] List all artifacts of product %s", productID)
+	artifacts, err := conn.ListProvisioningArtifactsWithContext(ctx, &servicecatalog.ListProvisioningArtifactsInput{
+		ProductId: aws.String(productID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing provisioning artifacts: %v", err)
+	}
+
+	for _, artifact := range artifacts.ProvisioningArtifactDetails {
+		switch {
+		case artifactID != "":
+			if aws.StringValue(artifact.Id) != artifactID {
+				continue
+			}
+		case artifactName != "":
+			if aws.StringValue(artifact.Name) != artifactName {
+				continue
+			}
+		default:
+			if !aws.BoolValue(artifact.Active) {
+				continue
+			}
+		}
+
+		if !aws.BoolValue(artifact.Active) {
+			return nil, fmt.Errorf("Provisioning artifact %s of product %s is not active", aws.StringValue(artifact.Name), productID)
+		}
+
+		return artifact, nil
+	}
+
+	if artifactID == "" && artifactName == "" {
+		return nil, fmt.Errorf("Could not find an active provisioning artifact of product %s", productID)
+	}
+
+	return nil, fmt.Errorf("Provisioning artifact %s%s not found in product %s", artifactID, artifactName, productID)
+}
+
+// findProvisionedAccount returns the ID of the provisioned product that vended the
+// account with the given account ID or email address. The search of provisioned products
+// doesn't match their outputs, so the outputs of all provisioned products are compared.
+// This finds accounts vended using another product than the Account Factory as well.
+func findProvisionedAccount(ctx context.Context, conn serviceCatalogAPI, limiter *rate.Limiter, accountIDOrEmail string) (string, error) {
+	input := &servicecatalog.SearchProvisionedProductsInput{
+		AccessLevelFilter: &servicecatalog.AccessLevelFilter{
+			Key:   aws.String(servicecatalog.AccessLevelFilterKeyAccount),
+			Value: aws.String("self"),
+		},
+	}
+
+	var ppIDs []string
+	log.Printf("[DEBUG] Search provisioned products for account %s", accountIDOrEmail)
+	err := conn.SearchProvisionedProductsPagesWithContext(ctx, input, func(page *servicecatalog.SearchProvisionedProductsOutput, lastPage bool) bool {
+		for _, pp := range page.ProvisionedProducts {
+			ppIDs = append(ppIDs, aws.StringValue(pp.Id))
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error searching provisioned accounts: %v", err)
+	}
+
+	for _, ppID := range ppIDs {
+		if err := limiter.Wait(ctx); err != nil {
+			return "", err
+		}
+
+		// Provisioned products that aren't accounts, or can't be read, don't stop the search.
+		outputs, err := provisionedProductOutputs(ctx, conn, ppID)
+		if err != nil {
+			log.Printf("[WARN] Error reading outputs of provisioned product %s, skipping it: %v", ppID, err)
+			continue
+		}
+
+		if outputs["AccountId"] == accountIDOrEmail || strings.EqualFold(outputs["AccountEmail"], accountIDOrEmail) {
+			return ppID, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w for %s", errNoProvisionedAccount, accountIDOrEmail)
+}
+
+// errNoProvisionedAccount is returned when no provisioned account is found.
+var errNoProvisionedAccount = errors.New("No provisioned account found")
+
+// provisionedProductOutputs returns the outputs of the provisioned product as a map.
+func provisionedProductOutputs(ctx context.Context, conn serviceCatalogAPI, ppID string) (map[string]string, error) {
+	outputs := make(map[string]string)
+
+	err := conn.GetProvisionedProductOutputsPagesWithContext(ctx, &servicecatalog.GetProvisionedProductOutputsInput{
+		ProvisionedProductId: aws.String(ppID),
+	}, func(page *servicecatalog.GetProvisionedProductOutputsOutput, lastPage bool) bool {
+		for _, output := range page.Outputs {
+			outputs[aws.StringValue(output.OutputKey)] = aws.StringValue(output.OutputValue)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return outputs, nil
+}
+
+// provisionedProductParameters returns the parameters used by the last provisioning
+// of the provisioned product. Service Catalog doesn't expose these, so they are read
+// from the CloudFormation stack backing the provisioned product.
+func provisionedProductParameters(ctx context.Context, scconn serviceCatalogAPI, cfconn cloudFormationAPI, ppID string) (map[string]string, error) {
+	parameters := make(map[string]string)
+
+	pps, err := scconn.SearchProvisionedProductsWithContext(ctx, &servicecatalog.SearchProvisionedProductsInput{
+		AccessLevelFilter: &servicecatalog.AccessLevelFilter{
+			Key:   aws.String(servicecatalog.AccessLevelFilterKeyAccount),
+			Value: aws.String("self"),
+		},
+		Filters: map[string][]*string{
+			"SearchQuery": {aws.String("id:" + ppID)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error searching provisioned account %s: %w", ppID, err)
+	}
+	if len(pps.ProvisionedProducts) == 0 || aws.StringValue(pps.ProvisionedProducts[0].PhysicalId) == "" {
+		log.Printf("[WARN] No stack found for provisioned account %s, unable to read provisioning parameters", ppID)
+		return parameters, nil
+	}
+
+	stackID := pps.ProvisionedProducts[0].PhysicalId
+
+	log.Printf("[DEBUG] Read provisioning parameters of provisioned account %s from stack %s", ppID, aws.StringValue(stackID))
+	stacks, err := cfconn.DescribeStacksWithContext(ctx, &cloudformation.DescribeStacksInput{
+		StackName: stackID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error reading provisioning parameters of provisioned account %s: %w", ppID, err)
+	}
+
+	for _, stack := range stacks.Stacks {
+		for _, parameter := range stack.Parameters {
+			parameters[aws.StringValue(parameter.ParameterKey)] = aws.StringValue(parameter.ParameterValue)
+		}
+	}
+
+	return parameters, nil
+}
+
+// accountOrganizationalUnitPath returns the path of the OU the account is placed in,
+// e.g. Root/Workloads/Prod.
+func accountOrganizationalUnitPath(ctx context.Context, conn organizationsAPI, accountID string) (string, error) {
+	var names []string
+
+	childID := accountID
+	for {
+		parents, err := conn.ListParentsWithContext(ctx, &organizations.ListParentsInput{
+			ChildId: aws.String(childID),
+		})
+		if err != nil {
+			return "", fmt.Errorf("error listing parents of %s: %v", childID, err)
+		}
+		if len(parents.Parents) == 0 {
+			return "", fmt.Errorf("no parent found for %s", childID)
+		}
+
+		parent := parents.Parents[0]
+		if aws.StringValue(parent.Type) == organizations.ParentTypeRoot {
+			break
+		}
+
+		ou, err := conn.DescribeOrganizationalUnitWithContext(ctx, &organizations.DescribeOrganizationalUnitInput{
+			OrganizationalUnitId: parent.Id,
+		})
+		if err != nil {
+			return "", fmt.Errorf("error describing organizational unit %s: %v", aws.StringValue(parent.Id), err)
+		}
+
+		names = append([]string{aws.StringValue(ou.OrganizationalUnit.Name)}, names...)
+		childID = aws.StringValue(parent.Id)
+	}
+
+	return strings.Join(append([]string{"Root"}, names...), "/"), nil
+}
+
+// suppressEquivalentOrganizationalUnitPath suppresses diffs between OU paths that only
+// differ in the optional leading Root segment.
+func suppressEquivalentOrganizationalUnitPath(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeOrganizationalUnitPath(old) == normalizeOrganizationalUnitPath(new)
+}
+
+// validateOrganizationalUnitPath validates the syntax of an OU path, which consists of
+// the names of nested OUs separated by slashes, optionally starting with Root.
+func validateOrganizationalUnitPath(v interface{}, k string) (ws []string, errs []error) {
+	path := v.(string)
+
+	for _, segment := range strings.Split(path, "/") {
+		if strings.TrimSpace(segment) == "" {
+			errs = append(errs, fmt.Errorf("%s must not contain empty segments, got %q", k, path))
+			return
+		}
+	}
+
+	if normalizeOrganizationalUnitPath(path) == "" {
+		errs = append(errs, fmt.Errorf("%s must contain an organizational unit below Root, got %q", k, path))
+	}
+
+	return
+}
+
+// normalizeOrganizationalUnitPath strips the optional leading Root segment of an OU path.
+func normalizeOrganizationalUnitPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if strings.EqualFold(segments[0], "Root") {
+		segments = segments[1:]
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// managedOrganizationalUnit resolves the configured OU path to the OU to place the account in.
+func managedOrganizationalUnit(ctx context.Context, conn organizationsAPI, d *schema.ResourceData) (*organizations.OrganizationalUnit, diag.Diagnostics) {
+	// Get organisation Root OU name and ID
+	roots, err := listRoots(ctx, conn)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	// Support both organizational_unit and organizational_unit_path until deprecated organizational_unit field is removed
+	ouKey := "organizational_unit_path"
+	if _, ok := d.GetOk("organizational_unit"); ok {
+		ouKey = "organizational_unit"
+	}
+
+	ouPath, ok := d.GetOk(ouKey)
+	if !ok {
+		return nil, diag.Errorf("one of organizational_unit or organizational_unit_path must be configured")
+	}
+
+	ou, err := returnChildOu(ctx, conn, ouPath.(string), aws.StringValue(roots[0].Id), aws.StringValue(roots[0].Name))
+	if err != nil {
+		return nil, diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid organizational unit path",
+			Detail:        err.Error(),
+			AttributePath: cty.GetAttrPath(ouKey),
+		}}
+	}
+
+	return ou, nil
+}
+
+// returnChildOu returns the child OU with the given path.
+func returnChildOu(ctx context.Context, conn organizationsAPI, path, ouID, ouName string) (*organizations.OrganizationalUnit, error) {
+	ou, err := findOrganizationalUnitByPath(ctx, conn, path, ouID, ouName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return ou.OrganizationalUnit, nil
+}
+
+// findOrganizationalUnitByPath resolves the OU path, relative to the parent with the
+// given ID and path, to the OU. The leading Root segment of the path is optional.
+func findOrganizationalUnitByPath(ctx context.Context, conn organizationsAPI, path, parentID, parentPath string, caseInsensitive bool) (*OrganizationalUnit, error) {
+	ou := &OrganizationalUnit{}
+
+	for _, v := range strings.Split(normalizeOrganizationalUnitPath(path), "/") {
+		if v == "" {
+			continue
+		}
+
+		log.Printf("[DEBUG] Listing OUs under parent: %s (%s)", parentPath, parentID)
+		children, err := listOrganizationalUnitsForParent(ctx, conn, parentID)
+		if err != nil {
+			return nil, fmt.Errorf("error listing organizational units for parent %s (%s): %v", parentPath, parentID, err)
+		}
+
+		var matches []*organizations.OrganizationalUnit
+		for _, child := range children {
+			name := aws.StringValue(child.Name)
+			if name == v {
+				matches = []*organizations.OrganizationalUnit{child}
+				break
+			}
+			if caseInsensitive && strings.EqualFold(name, v) {
+				matches = append(matches, child)
+			}
+		}
+
+		switch len(matches) {
+		case 0:
+			return nil, &organizationalUnitNotFoundError{name: v, parentPath: parentPath, parentID: parentID, siblings: children}
+		case 1:
+		default:
+			return nil, fmt.Errorf("multiple organizational units matching %s found in parent %s (%s)", v, parentPath, parentID)
+		}
+
+		childPath := parentPath + "/" + aws.StringValue(matches[0].Name)
+		ou = &OrganizationalUnit{
+			OrganizationalUnit: matches[0],
+			Path:               aws.String(childPath),
+			ParentId:           aws.String(parentID),
+			ParentPath:         aws.String(parentPath),
+		}
+
+		parentID = aws.StringValue(matches[0].Id)
+		parentPath = childPath
+	}
+
+	if ou.OrganizationalUnit == nil {
+		return nil, fmt.Errorf("organizational unit path %q does not contain any organizational units", path)
+	}
+
+	return ou, nil
+}
+
+// listOrganizationalUnitsForParent returns the OUs directly under the parent.
+func listOrganizationalUnitsForParent(ctx context.Context, conn organizationsAPI, parentID string) ([]*organizations.OrganizationalUnit, error) {
+	var ous []*organizations.OrganizationalUnit
+	err := conn.ListOrganizationalUnitsForParentPagesWithContext(ctx, &organizations.ListOrganizationalUnitsForParentInput{
+		ParentId: aws.String(parentID),
+	}, func(page *organizations.ListOrganizationalUnitsForParentOutput, lastPage bool) bool {
+		ous = append(ous, page.OrganizationalUnits...)
+		return !lastPage
+	})
+
+	return ous, err
+}
+
+// organizationalUnitNotFoundError is returned when an OU is not found in the parent.
+// Its message lists the names of the siblings closest to the name.
+type organizationalUnitNotFoundError struct {
+	name       string
+	parentPath string
+	parentID   string
+	siblings   []*organizations.OrganizationalUnit
+}
+
+func (e *organizationalUnitNotFoundError) Error() string {
+	if len(e.siblings) == 0 {
+		return fmt.Sprintf("organizational unit %s not found in parent %s (%s), which has no organizational units", e.name, e.parentPath, e.parentID)
+	}
+
+	var names []string
+	for _, sibling := range e.siblings {
+		names = append(names, aws.StringValue(sibling.Name))
+	}
+
+	return fmt.Sprintf("organizational unit %s not found in parent %s (%s), did you mean: %s?",
+		e.name, e.parentPath, e.parentID, strings.Join(closestNames(e.name, names, 5), ", "))
+}
+
+// closestNames returns at most n of the names, ordered by their similarity to the name.
+func closestNames(name string, names []string, n int) []string {
+	distances := make(map[string]int, len(names))
+	for _, v := range names {
+		distances[v] = levenshtein(strings.ToLower(name), strings.ToLower(v))
+	}
+
+	sorted := append([]string(nil), names...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if distances[sorted[i]] != distances[sorted[j]] {
+			return distances[sorted[i]] < distances[sorted[j]]
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+
+	return sorted
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr := make([]int, len(rb)+1)
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+
+	return prev[len(rb)]
+}
+
+// The delays used when polling the provisioning status, the delay between polls
+// is doubled (with jitter) after every poll until the maximum is reached.
+var (
+	provisioningMinPollDelay = 5 * time.Second
+	provisioningMaxPollDelay = 60 * time.Second
+)
+
+// waitForProvisioning waits until the provisioning finished or the context is done.
+func waitForProvisioning(ctx context.Context, name string, recordID *string, meta interface{}) error {
+	scconn := meta.(*Client).AWSClient.scconn
+
+	record := &servicecatalog.DescribeRecordInput{
+		Id: recordID,
+	}
+
+	delay := provisioningMinPollDelay
+	lastStatus := "UNKNOWN"
+
+	for {
+		// Get the provisioning status.
+		status, err := scconn.DescribeRecordWithContext(ctx, record)
+		if err != nil {
+			if ctx.Err() != nil {
+				return provisioningContextError(ctx, name, recordID, lastStatus)
+			}
+			return fmt.Errorf("Error reading provisioning status of account %s: %v", name, err)
+		}
+		lastStatus = aws.StringValue(status.RecordDetail.Status)
+
+		// If the provisioning succeeded we are done.
+		if lastStatus == servicecatalog.RecordStatusSucceeded {
+			break
+		}
+
+		// If the provisioning failed we try to cleanup the tainted account.
+		if lastStatus == servicecatalog.RecordStatusFailed {
+			if len(status.RecordDetail.RecordErrors) == 0 {
+				return fmt.Errorf("Provisioning account %s failed (record ID: %s)", name, aws.StringValue(recordID))
+			}
+			return fmt.Errorf("Provisioning account %s failed: %s", name, *status.RecordDetail.RecordErrors[0].Description)
+		}
+
+		log.Printf("[DEBUG] Provisioning of account %s is %s (record ID: %s), checking again in %s", name, lastStatus, aws.StringValue(recordID), delay)
+
+		// Wait before checking the status again, or stop when the context is done.
+		select {
+		case <-ctx.Done():
+			return provisioningContextError(ctx, name, recordID, lastStatus)
+		case <-time.After(delay/2 + rand.N(delay/2+1)):
+		}
+
+		delay = min(delay*2, provisioningMaxPollDelay)
+	}
+
+	return nil
+}
+
+// provisioningContextError returns the error for a provisioning that was interrupted
+// because the context timed out or was cancelled.
+func provisioningContextError(ctx context.Context, name string, recordID *string, lastStatus string) error {
+	reason := "was cancelled"
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		reason = "timed out"
+	}
+
+	return fmt.Errorf("Waiting for provisioning of account %s %s (record ID: %s, last status: %s); "+
+		"check the record in the Service Catalog console", name, reason, aws.StringValue(recordID), lastStatus)
+}
@@ -0,0 +1,191 @@
+package mcaf
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/account"
+	"github.com/hashicorp/aws-sdk-go-base/tfawserr"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceAWSAccountAlternateContact() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: checkProvider("aws", resourceAWSAccountAlternateContactCreate),
+		ReadContext:   checkProvider("aws", resourceAWSAccountAlternateContactRead),
+		UpdateContext: checkProvider("aws", resourceAWSAccountAlternateContactUpdate),
+		DeleteContext: checkProvider("aws", resourceAWSAccountAlternateContactDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceAWSAccountAlternateContactImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"account_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(accountIDRegexp, "must be a 12 digit account ID"),
+			},
+			"alternate_contact_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(account.AlternateContactType_Values(), false),
+			},
+			"email_address": {
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.All(
+					validation.StringLenBetween(1, 254),
+					validation.StringMatch(accountEmailRegexp, "must be a valid email address"),
+				),
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringLenBetween(1, 64),
+			},
+			"phone_number": {
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.All(
+					validation.StringLenBetween(1, 25),
+					validation.StringMatch(alternateContactPhoneNumberRegexp, "must only contain digits, spaces, parentheses, plus and minus signs"),
+				),
+			},
+			"title": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringLenBetween(1, 50),
+			},
+		},
+	}
+}
+
+var alternateContactPhoneNumberRegexp = regexp.MustCompile(`^[\s0-9()+-]+$`)
+
+func resourceAWSAccountAlternateContactCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	accountID := d.Get("account_id").(string)
+	contactType := d.Get("alternate_contact_type").(string)
+
+	if err := putAlternateContact(ctx, d, meta); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(accountID + "/" + contactType)
+
+	return resourceAWSAccountAlternateContactRead(ctx, d, meta)
+}
+
+func resourceAWSAccountAlternateContactRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*Client).AWSClient.acctconn
+
+	accountID := d.Get("account_id").(string)
+	contactType := d.Get("alternate_contact_type").(string)
+
+	log.Printf("[DEBUG] Read %s alternate contact of account %s", contactType, accountID)
+	output, err := conn.GetAlternateContactWithContext(ctx, &account.GetAlternateContactInput{
+		AccountId:            alternateContactAccountID(meta, accountID),
+		AlternateContactType: aws.String(contactType),
+	})
+	if tfawserr.ErrCodeEquals(err, account.ErrCodeResourceNotFoundException) {
+		log.Printf("[WARN] %s alternate contact of account %s not found, removing from state", contactType, accountID)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("Error reading %s alternate contact of account %s: %v", contactType, accountID, err)
+	}
+
+	contact := output.AlternateContact
+	d.Set("email_address", contact.EmailAddress)
+	d.Set("name", contact.Name)
+	d.Set("phone_number", contact.PhoneNumber)
+	d.Set("title", contact.Title)
+
+	return nil
+}
+
+func resourceAWSAccountAlternateContactUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if err := putAlternateContact(ctx, d, meta); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceAWSAccountAlternateContactRead(ctx, d, meta)
+}
+
+func resourceAWSAccountAlternateContactDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*Client).AWSClient.acctconn
+
+	accountID := d.Get("account_id").(string)
+	contactType := d.Get("alternate_contact_type").(string)
+
+	log.Printf("[DEBUG] Delete %s alternate contact of account %s", contactType, accountID)
+	_, err := conn.DeleteAlternateContactWithContext(ctx, &account.DeleteAlternateContactInput{
+		AccountId:            alternateContactAccountID(meta, accountID),
+		AlternateContactType: aws.String(contactType),
+	})
+	if tfawserr.ErrCodeEquals(err, account.ErrCodeResourceNotFoundException) {
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("Error deleting %s alternate contact of account %s: %v", contactType, accountID, err)
+	}
+
+	return nil
+}
+
+func resourceAWSAccountAlternateContactImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// The import ID is the account ID and the contact type, e.g. 123456789012/SECURITY.
+	accountID, contactType, ok := strings.Cut(d.Id(), "/")
+	if !ok || !accountIDRegexp.MatchString(accountID) {
+		return nil, fmt.Errorf("Invalid import ID %q: expected <account ID>/<alternate contact type>", d.Id())
+	}
+
+	d.Set("account_id", accountID)
+	d.Set("alternate_contact_type", strings.ToUpper(contactType))
+	d.SetId(accountID + "/" + strings.ToUpper(contactType))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// putAlternateContact creates or updates the alternate contact from the configuration.
+func putAlternateContact(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*Client).AWSClient.acctconn
+
+	accountID := d.Get("account_id").(string)
+	contactType := d.Get("alternate_contact_type").(string)
+
+	log.Printf("[DEBUG] Put %s alternate contact of account %s", contactType, accountID)
+	_, err := conn.PutAlternateContactWithContext(ctx, &account.PutAlternateContactInput{
+		AccountId:            alternateContactAccountID(meta, accountID),
+		AlternateContactType: aws.String(contactType),
+		EmailAddress:         aws.String(d.Get("email_address").(string)),
+		Name:                 aws.String(d.Get("name").(string)),
+		PhoneNumber:          aws.String(d.Get("phone_number").(string)),
+		Title:                aws.String(d.Get("title").(string)),
+	})
+	if err != nil {
+		return fmt.Errorf("Error putting %s alternate contact of account %s: %v", contactType, accountID, err)
+	}
+
+	return nil
+}
+
+// alternateContactAccountID returns the account ID to pass to the Account Management API.
+// The API rejects the ID of the management account itself, so it is omitted for the
+// account the provider is authenticated in.
+func alternateContactAccountID(meta interface{}, accountID string) *string {
+	if accountID == meta.(*Client).AWSClient.accountID {
+		return nil
+	}
+
+	return aws.String(accountID)
+}
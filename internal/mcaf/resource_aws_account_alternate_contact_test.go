@@ -0,0 +1,146 @@
+package mcaf
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitResourceAWSAccountAlternateContact_basic(t *testing.T) {
+	resourceName := "mcaf_aws_account_alternate_contact.test"
+
+	fake := newFakeAWS(t)
+	accountID := fake.addAccount(fakeRootID, "test", "test@example.com")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testUnitPreCheck(t)
+		},
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testUnitCheckAWSAccountAlternateContactDestroy(fake),
+		Steps: []resource.TestStep{
+			{
+				Config: fake.providerConfig() + testUnitResourceAWSAccountAlternateContactConfig(accountID, "Security Team"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "id", accountID+"/SECURITY"),
+					resource.TestCheckResourceAttr(resourceName, "name", "Security Team"),
+				),
+			},
+			{
+				Config: fake.providerConfig() + testUnitResourceAWSAccountAlternateContactConfig(accountID, "CSIRT"),
+				Check:  resource.TestCheckResourceAttr(resourceName, "name", "CSIRT"),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceAWSAccountAlternateContact_lifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	accountID := fake.addAccount(fakeRootID, "test", "test@example.com")
+	meta := fake.client(t)
+	meta.AWSClient.accountID = fakeManagementAccountID
+
+	for _, id := range []string{accountID, fakeManagementAccountID} {
+		d := testResourceAWSAccountAlternateContactData(t, id, "Security Team")
+		if diags := resourceAWSAccountAlternateContactCreate(ctx, d, meta); diags.HasError() {
+			t.Fatalf("err: %v", diags)
+		}
+		if d.Id() != id+"/SECURITY" {
+			t.Fatalf("expected ID %s/SECURITY, got %s", id, d.Id())
+		}
+		if contact := fake.alternateContacts[d.Id()]; contact == nil || *contact.Name != "Security Team" {
+			t.Fatalf("expected the alternate contact of %s to be put, got %v", id, contact)
+		}
+	}
+
+	d := testResourceAWSAccountAlternateContactData(t, accountID, "Security Team")
+	d.SetId(accountID + "/SECURITY")
+
+	// Changes made outside of Terraform should be detected.
+	name := "CSIRT"
+	fake.alternateContacts[d.Id()].Name = &name
+	if diags := resourceAWSAccountAlternateContactRead(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if v := d.Get("name").(string); v != "CSIRT" {
+		t.Fatalf("expected name CSIRT, got %q", v)
+	}
+
+	imported := testResourceAWSAccountAlternateContactData(t, "", "")
+	imported.SetId(accountID + "/security")
+	if _, err := resourceAWSAccountAlternateContactImport(ctx, imported, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if imported.Id() != d.Id() || imported.Get("alternate_contact_type").(string) != "SECURITY" {
+		t.Fatalf("expected import to resolve to %s, got %s", d.Id(), imported.Id())
+	}
+
+	if diags := resourceAWSAccountAlternateContactDelete(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if _, ok := fake.alternateContacts[d.Id()]; ok {
+		t.Fatalf("expected the alternate contact to be deleted")
+	}
+
+	// Reading a deleted alternate contact should remove it from the state.
+	if diags := resourceAWSAccountAlternateContactRead(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected the alternate contact to be removed from the state")
+	}
+}
+
+func testResourceAWSAccountAlternateContactData(t *testing.T, accountID, name string) *schema.ResourceData {
+	raw := map[string]interface{}{
+		"alternate_contact_type": "SECURITY",
+		"email_address":          "security@example.com",
+		"phone_number":           "+31 20 123 4567",
+		"title":                  "Security",
+	}
+	if accountID != "" {
+		raw["account_id"] = accountID
+	}
+	if name != "" {
+		raw["name"] = name
+	}
+
+	return schema.TestResourceDataRaw(t, resourceAWSAccountAlternateContact().Schema, raw)
+}
+
+func testUnitCheckAWSAccountAlternateContactDestroy(fake *fakeAWS) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		for key := range fake.alternateContacts {
+			return fmt.Errorf("alternate contact %s still exists", key)
+		}
+
+		return nil
+	}
+}
+
+func testUnitResourceAWSAccountAlternateContactConfig(accountID, name string) string {
+	return fmt.Sprintf(`
+resource "mcaf_aws_account_alternate_contact" "test" {
+  account_id             = %q
+  alternate_contact_type = "SECURITY"
+  name                   = %q
+  title                  = "Security"
+  email_address          = "security@example.com"
+  phone_number           = "+31 20 123 4567"
+}
+`, accountID, name)
+}
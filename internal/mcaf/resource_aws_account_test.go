@@ -0,0 +1,838 @@
+package mcaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/servicecatalog"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitResourceAWSAccount_basic(t *testing.T) {
+	resourceName := "mcaf_aws_account.test"
+
+	fake := newFakeAWS(t)
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	prod := fake.addOU(workloads, "Prod")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testUnitPreCheck(t)
+		},
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testUnitCheckAWSAccountDestroy(fake),
+		Steps: []resource.TestStep{
+			{
+				Config: fake.providerConfig() + testUnitResourceAWSAccountConfig("Workloads/Prod"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(resourceName, "account_id"),
+					resource.TestCheckResourceAttr(resourceName, "organizational_unit_path", "Workloads/Prod"),
+					testUnitCheckAWSAccountParent(fake, resourceName, prod),
+				),
+			},
+			{
+				Config: fake.providerConfig() + testUnitResourceAWSAccountConfig("Root/Workloads"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "organizational_unit_path", "Root/Workloads"),
+					testUnitCheckAWSAccountParent(fake, resourceName, workloads),
+				),
+			},
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateIdFunc:       testUnitAWSAccountImportStateIdFunc(resourceName, "email"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"organizational_unit_path", "product_name", "provisioning_artifact_name"},
+			},
+		},
+	})
+}
+
+func TestUnitResourceAWSAccount_failedRecord(t *testing.T) {
+	fake := newFakeAWS(t)
+	fake.addOU(fakeRootID, "Workloads")
+	fake.failRecords["test"] = "Account email address is already in use"
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testUnitPreCheck(t)
+		},
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testUnitCheckAWSAccountDestroy(fake),
+		Steps: []resource.TestStep{
+			{
+				Config:      fake.providerConfig() + testUnitResourceAWSAccountConfig("Workloads"),
+				ExpectError: regexp.MustCompile("Account email address is already in use"),
+			},
+		},
+	})
+}
+
+func TestResourceAWSAccount_lifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	prod := fake.addOU(workloads, "Prod")
+	meta := fake.client(t)
+
+	d := testResourceAWSAccountData(t, "Workloads/Prod")
+
+	if diags := resourceAWSAccountCreate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if !strings.HasPrefix(d.Id(), "pp-") {
+		t.Fatalf("expected a provisioned product ID, got %q", d.Id())
+	}
+
+	accountID := d.Get("account_id").(string)
+	if parent := fake.accountParent(accountID); parent != prod {
+		t.Fatalf("expected account in %s, got %s", prod, parent)
+	}
+	if v := d.Get("product_id").(string); v != fakeProductID {
+		t.Fatalf("expected product_id %s, got %q", fakeProductID, v)
+	}
+	if v := d.Get("provisioning_artifact_id").(string); v != fakeArtifactIDActive {
+		t.Fatalf("expected provisioning_artifact_id %s, got %q", fakeArtifactIDActive, v)
+	}
+
+	// Moving the account outside of Terraform should be detected.
+	fake.setAccountParent(accountID, workloads)
+	if diags := resourceAWSAccountRead(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if v := d.Get("organizational_unit_path").(string); v != "Root/Workloads" {
+		t.Fatalf("expected organizational_unit_path Root/Workloads, got %q", v)
+	}
+	if v := d.Get("sso.0.firstname").(string); v != "Control" {
+		t.Fatalf("expected sso firstname Control, got %q", v)
+	}
+
+	// Missing permissions to read the provisioning parameters should only skip SSO drift detection.
+	pp := fake.provisionedProducts[d.Id()]
+	fake.errors["DescribeStacks/"+pp.stackID] = "AccessDenied"
+	diags := resourceAWSAccountRead(ctx, d, meta)
+	if diags.HasError() || len(diags) != 1 || diags[0].Summary != "Unable to detect drift of the sso details" {
+		t.Fatalf("expected an sso drift warning, got %v", diags)
+	}
+	if v := d.Get("sso.0.firstname").(string); v != "Control" {
+		t.Fatalf("expected sso firstname Control, got %q", v)
+	}
+	delete(fake.errors, "DescribeStacks/"+pp.stackID)
+
+	// Stacks without the SSO parameters shouldn't clear the sso details.
+	ssoParameters := make(map[string]string)
+	for _, key := range []string{"SSOUserFirstName", "SSOUserLastName", "SSOUserEmail"} {
+		ssoParameters[key] = pp.parameters[key]
+		delete(pp.parameters, key)
+	}
+	if diags := resourceAWSAccountRead(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if v := d.Get("sso.0.firstname").(string); v != "Control" {
+		t.Fatalf("expected sso firstname Control, got %q", v)
+	}
+	for key, value := range ssoParameters {
+		pp.parameters[key] = value
+	}
+
+	// Updating the account should move it back.
+	d.Set("organizational_unit_path", "Workloads/Prod")
+	if diags := resourceAWSAccountUpdate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if parent := fake.accountParent(accountID); parent != prod {
+		t.Fatalf("expected account in %s, got %s", prod, parent)
+	}
+
+	// Importing by account ID or email should resolve the provisioned product.
+	for _, importID := range []string{accountID, "TEST@example.com", d.Id()} {
+		imported := testResourceAWSAccountData(t, "")
+		imported.SetId(importID)
+
+		if _, err := resourceAWSAccountImport(ctx, imported, meta); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if imported.Id() != d.Id() {
+			t.Fatalf("expected import of %s to resolve to %s, got %s", importID, d.Id(), imported.Id())
+		}
+	}
+
+	if diags := resourceAWSAccountDelete(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if parent := fake.accountParent(accountID); parent != fakeRootID {
+		t.Fatalf("expected account in the root, got %s", parent)
+	}
+
+	// Reading a deleted account should remove it from the state.
+	if diags := resourceAWSAccountRead(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected account to be removed from the state")
+	}
+}
+
+func TestResourceAWSAccount_ouMoveStrategyOrganizations(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	prod := fake.addOU(workloads, "Prod")
+	meta := fake.client(t)
+
+	d := testResourceAWSAccountData(t, "Workloads/Prod")
+	d.Set("ou_move_strategy", ouMoveStrategyOrganizations)
+
+	if diags := resourceAWSAccountCreate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	accountID := d.Get("account_id").(string)
+
+	raw := testResourceAWSAccountRaw("Workloads")
+	raw["ou_move_strategy"] = ouMoveStrategyOrganizations
+
+	// Only moving the account should not run the Account Factory.
+	d = testResourceDataWithChanges(t, resourceAWSAccount(), d.State(), raw, meta)
+	if diags := resourceAWSAccountUpdate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if parent := fake.accountParent(accountID); parent != workloads {
+		t.Fatalf("expected account in %s, got %s", workloads, parent)
+	}
+	if n := fake.requestCount("UpdateProvisionedProduct"); n != 0 {
+		t.Fatalf("expected the Account Factory not to run, got %d updates", n)
+	}
+	if v := d.Get("last_ou_move_strategy").(string); v != ouMoveStrategyOrganizations {
+		t.Fatalf("expected last_ou_move_strategy %s, got %q", ouMoveStrategyOrganizations, v)
+	}
+
+	// Changing the SSO details as well requires the Account Factory to re-register the account.
+	raw["organizational_unit_path"] = "Workloads/Prod"
+	raw["sso"].([]interface{})[0].(map[string]interface{})["email"] = "sso@example.com"
+
+	d = testResourceDataWithChanges(t, resourceAWSAccount(), d.State(), raw, meta)
+	if diags := resourceAWSAccountUpdate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if parent := fake.accountParent(accountID); parent != prod {
+		t.Fatalf("expected account in %s, got %s", prod, parent)
+	}
+	if n := fake.requestCount("UpdateProvisionedProduct"); n != 1 {
+		t.Fatalf("expected the Account Factory to run once, got %d updates", n)
+	}
+	if v := d.Get("last_ou_move_strategy").(string); v != ouMoveStrategyOrganizationsReregistered {
+		t.Fatalf("expected last_ou_move_strategy %s, got %q", ouMoveStrategyOrganizationsReregistered, v)
+	}
+}
+
+func TestResourceAWSAccount_product(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	fake.addOU(fakeRootID, "Workloads")
+	productID := fake.addProduct("Custom Account Factory",
+		&fakeArtifact{id: "pa-custom-v1", name: "v1", active: true},
+		&fakeArtifact{id: "pa-custom-v2", name: "v2", active: true},
+		&fakeArtifact{id: "pa-custom-v3", name: "v3"},
+	)
+	meta := fake.client(t)
+
+	raw := testResourceAWSAccountRaw("Workloads")
+	raw["product_name"] = "Custom Account Factory"
+	raw["provisioning_artifact_name"] = "v1"
+
+	d := schema.TestResourceDataRaw(t, resourceAWSAccount().Schema, raw)
+	if diags := resourceAWSAccountCreate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	for k, v := range map[string]string{
+		"product_id":                 productID,
+		"product_name":               "Custom Account Factory",
+		"provisioning_artifact_id":   "pa-custom-v1",
+		"provisioning_artifact_name": "v1",
+	} {
+		if got := d.Get(k).(string); got != v {
+			t.Fatalf("expected %s %q, got %q", k, v, got)
+		}
+	}
+
+	// Selecting another artifact should update the provisioned product.
+	raw["provisioning_artifact_name"] = "v2"
+
+	d = testResourceDataWithChanges(t, resourceAWSAccount(), d.State(), raw, meta)
+	if diags := resourceAWSAccountUpdate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if v := fake.provisionedProducts[d.Id()].artifactID; v != "pa-custom-v2" {
+		t.Fatalf("expected provisioned product to use artifact pa-custom-v2, got %s", v)
+	}
+	if n := fake.requestCount("UpdateProvisionedProduct"); n != 1 {
+		t.Fatalf("expected 1 update, got %d", n)
+	}
+
+	// Selecting the same artifact by ID should not run the Account Factory.
+	delete(raw, "provisioning_artifact_name")
+	raw["provisioning_artifact_id"] = "pa-custom-v2"
+	raw["product_id"] = productID
+	delete(raw, "product_name")
+
+	d = testResourceDataWithChanges(t, resourceAWSAccount(), d.State(), raw, meta)
+	if diags := resourceAWSAccountUpdate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if n := fake.requestCount("UpdateProvisionedProduct"); n != 1 {
+		t.Fatalf("expected no additional updates, got %d", n)
+	}
+
+	// Inactive artifacts cannot be used.
+	raw["provisioning_artifact_id"] = "pa-custom-v3"
+
+	d = testResourceDataWithChanges(t, resourceAWSAccount(), d.State(), raw, meta)
+	diags := resourceAWSAccountUpdate(ctx, d, meta)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "is not active") {
+		t.Fatalf("expected an inactive artifact error, got %v", diags)
+	}
+}
+
+func TestResourceAWSAccount_autoUpdateArtifact(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	fake.addOU(fakeRootID, "Workloads")
+	meta := fake.client(t)
+
+	raw := testResourceAWSAccountRaw("Workloads")
+	raw["auto_update_artifact"] = true
+
+	d := schema.TestResourceDataRaw(t, resourceAWSAccount().Schema, raw)
+	if diags := resourceAWSAccountCreate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	// Updating Control Tower activates a new Account Factory artifact.
+	fake.activateArtifact(fakeProductID, "pa-new", "v3")
+
+	// Without auto_update_artifact the provisioned account keeps its artifact, but the
+	// outdated artifact is reported.
+	raw["auto_update_artifact"] = false
+	outdated := testResourceDataWithChanges(t, resourceAWSAccount(), d.State(), raw, meta)
+	if outdated.HasChange("provisioning_artifact_id") {
+		t.Fatalf("expected no change of provisioning_artifact_id")
+	}
+	diags := resourceAWSAccountRead(ctx, outdated, meta)
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || !strings.Contains(diags[0].Detail, "pa-new (v3) is the active artifact") {
+		t.Fatalf("expected an outdated artifact warning, got %v", diags)
+	}
+
+	raw["auto_update_artifact"] = true
+	d = testResourceDataWithChanges(t, resourceAWSAccount(), d.State(), raw, meta)
+	if !d.HasChange("provisioning_artifact_id") {
+		t.Fatalf("expected a planned change of provisioning_artifact_id")
+	}
+	if diags := resourceAWSAccountUpdate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	if v := fake.provisionedProducts[d.Id()].artifactID; v != "pa-new" {
+		t.Fatalf("expected provisioned product to use artifact pa-new, got %s", v)
+	}
+	if v := d.Get("provisioning_artifact_name").(string); v != "v3" {
+		t.Fatalf("expected provisioning_artifact_name v3, got %q", v)
+	}
+	if n := fake.requestCount("UpdateProvisionedProduct"); n != 1 {
+		t.Fatalf("expected 1 update, got %d", n)
+	}
+
+	// Once updated, no further changes are planned.
+	if d := testResourceDataWithChanges(t, resourceAWSAccount(), d.State(), raw, meta); d.HasChange("provisioning_artifact_id") {
+		t.Fatalf("expected no change of provisioning_artifact_id")
+	}
+}
+
+func TestResourceAWSAccount_customizeDiffOrganizationalUnit(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	workloads := fake.addOU(fakeRootID, "Workloads")
+	fake.addOU(workloads, "Prod")
+	fake.addOU(workloads, "Production")
+	fake.addOU(workloads, "Dev")
+	meta := fake.client(t)
+
+	cases := []struct {
+		path     string
+		validate bool
+		err      string
+	}{
+		{path: "Workloads/Prod", validate: true},
+		{path: "Root/Workloads/Prod", validate: true},
+		{path: "Workloads/Prdo", validate: true, err: "did you mean: Prod, Dev, Production?"},
+		{path: "Workload/Prod", validate: true, err: "organizational unit Workload not found in parent Root"},
+		{path: "Workloads/Prdo", validate: false},
+		{path: testUnknownValue, validate: true},
+	}
+
+	for _, tc := range cases {
+		raw := testResourceAWSAccountRaw(tc.path)
+		raw["validate_organizational_unit_path"] = tc.validate
+
+		_, err := resourceAWSAccount().Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), meta)
+		if tc.err == "" && err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.path, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Fatalf("%s: expected error containing %q, got %v", tc.path, tc.err, err)
+		}
+	}
+}
+
+func TestResourceAWSAccount_customizeDiffEmail(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	fake.addOU(fakeRootID, "Workloads")
+	fake.addAccount(fakeRootID, "existing", "Existing@example.com")
+	meta := fake.client(t)
+
+	raw := testResourceAWSAccountRaw("Workloads")
+	raw["email"] = "existing@example.com"
+
+	_, err := resourceAWSAccount().Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), meta)
+	if err == nil || !strings.Contains(err.Error(), "already used by account existing") {
+		t.Fatalf("expected an error for the used email, got %v", err)
+	}
+
+	raw["email"] = "test@example.com"
+	if _, err := resourceAWSAccount().Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestResourceAWSAccount_validation(t *testing.T) {
+	cases := []struct {
+		attribute string
+		value     string
+		valid     bool
+	}{
+		{attribute: "name", value: "My Account-1", valid: true},
+		{attribute: "name", value: strings.Repeat("a", 51)},
+		{attribute: "name", value: "Accoünt"},
+		{attribute: "name", value: " "},
+		{attribute: "email", value: "a@example.com", valid: true},
+		{attribute: "email", value: "a@b.c"},
+		{attribute: "email", value: "not-an-email"},
+		{attribute: "email", value: strings.Repeat("a", 53) + "@example.com"},
+		{attribute: "sso.0.firstname", value: "Control Tower", valid: true},
+		{attribute: "sso.0.firstname", value: strings.Repeat("a", 51)},
+		{attribute: "sso.0.lastname", value: ""},
+		{attribute: "sso.0.email", value: "control tower@example.com"},
+	}
+
+	for _, tc := range cases {
+		raw := testResourceAWSAccountRaw("Workloads")
+		if attribute, ok := strings.CutPrefix(tc.attribute, "sso.0."); ok {
+			raw["sso"].([]interface{})[0].(map[string]interface{})[attribute] = tc.value
+		} else {
+			raw[tc.attribute] = tc.value
+		}
+
+		diags := resourceAWSAccount().Validate(terraform.NewResourceConfigRaw(raw))
+		if tc.valid && diags.HasError() {
+			t.Fatalf("%s %q: unexpected errors: %v", tc.attribute, tc.value, diags)
+		}
+		if !tc.valid && !diags.HasError() {
+			t.Fatalf("%s %q: expected an error", tc.attribute, tc.value)
+		}
+	}
+}
+
+// testUnknownValue is the value Terraform uses in raw configurations for values that
+// are only known during apply.
+const testUnknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func TestValidateOrganizationalUnitPath(t *testing.T) {
+	cases := map[string]bool{
+		"Workloads":           true,
+		"Workloads/Prod":      true,
+		"Root/Workloads/Prod": true,
+		"root/Workloads":      true,
+		"Root":                false,
+		"":                    false,
+		"/Workloads":          false,
+		"Workloads/":          false,
+		"Workloads//Prod":     false,
+		"Workloads/ /Prod":    false,
+	}
+
+	for path, valid := range cases {
+		_, errs := validateOrganizationalUnitPath(path, "organizational_unit_path")
+		if valid && len(errs) > 0 {
+			t.Fatalf("%q: unexpected errors: %v", path, errs)
+		}
+		if !valid && len(errs) == 0 {
+			t.Fatalf("%q: expected an error", path)
+		}
+	}
+}
+
+func TestResourceAWSAccount_tags(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	fake.addOU(fakeRootID, "Workloads")
+	meta := fake.client(t)
+	meta.AWSClient.defaultTags = map[string]string{"Environment": "default", "Owner": "platform"}
+
+	raw := testResourceAWSAccountRaw("Workloads")
+	raw["tags"] = map[string]interface{}{"Environment": "prod", "Team": "a"}
+
+	d := testResourceDataWithChanges(t, resourceAWSAccount(), nil, raw, meta)
+	if diags := resourceAWSAccountCreate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	accountID := d.Get("account_id").(string)
+
+	expected := map[string]string{"Environment": "prod", "Owner": "platform", "Team": "a"}
+	if !reflect.DeepEqual(fake.tags[accountID], expected) {
+		t.Fatalf("expected account tags %v, got %v", expected, fake.tags[accountID])
+	}
+	if !reflect.DeepEqual(fake.provisionedProducts[d.Id()].tags, expected) {
+		t.Fatalf("expected provisioned product tags %v, got %v", expected, fake.provisionedProducts[d.Id()].tags)
+	}
+	if v := d.Get("tags").(map[string]interface{}); len(v) != 2 {
+		t.Fatalf("expected 2 tags without the default tags, got %v", v)
+	}
+	if v := d.Get("tags_all").(map[string]interface{}); len(v) != 3 {
+		t.Fatalf("expected 3 tags including the default tags, got %v", v)
+	}
+
+	// Tags added outside of Terraform should be detected.
+	fake.setTags(accountID, map[string]string{"Environment": "prod", "Extra": "x", "Owner": "platform", "Team": "a"})
+	if diags := resourceAWSAccountRead(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if v := d.Get("tags.Extra").(string); v != "x" {
+		t.Fatalf("expected the Extra tag to be detected, got %v", d.Get("tags"))
+	}
+
+	// Updating the tags should not run the Account Factory.
+	raw["tags"] = map[string]interface{}{"Team": "b"}
+
+	d = testResourceDataWithChanges(t, resourceAWSAccount(), d.State(), raw, meta)
+	if diags := resourceAWSAccountUpdate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	expected = map[string]string{"Environment": "default", "Owner": "platform", "Team": "b"}
+	if !reflect.DeepEqual(fake.tags[accountID], expected) {
+		t.Fatalf("expected account tags %v, got %v", expected, fake.tags[accountID])
+	}
+	if n := fake.requestCount("UpdateProvisionedProduct"); n != 0 {
+		t.Fatalf("expected the Account Factory not to run, got %d updates", n)
+	}
+}
+
+func TestResourceAWSAccount_deletionMode(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		mode       string
+		protection bool
+		err        string
+	}{
+		{mode: deletionModeUnmanage, protection: true, err: "deletion_protection is enabled"},
+		{mode: deletionModeUnmanage},
+		{mode: deletionModeRetain},
+		{mode: deletionModeMoveToOU},
+		{mode: deletionModeClose},
+	}
+
+	for _, tc := range cases {
+		fake := newFakeAWS(t)
+		workloads := fake.addOU(fakeRootID, "Workloads")
+		quarantine := fake.addOU(fakeRootID, "Quarantine")
+		meta := fake.client(t)
+
+		raw := testResourceAWSAccountRaw("Workloads")
+		raw["deletion_mode"] = tc.mode
+		raw["deletion_protection"] = tc.protection
+		if tc.mode == deletionModeMoveToOU {
+			raw["deletion_organizational_unit_path"] = "Quarantine"
+		}
+
+		d := testResourceDataWithChanges(t, resourceAWSAccount(), nil, raw, meta)
+		if diags := resourceAWSAccountCreate(ctx, d, meta); diags.HasError() {
+			t.Fatalf("%s: err: %v", tc.mode, diags)
+		}
+		ppID := d.Id()
+		accountID := d.Get("account_id").(string)
+
+		// The provisioning slot should be released while waiting for the account to close.
+		var heldWhileClosing bool
+		fake.hooks["DescribeAccount"] = func() {
+			meta.provisioningQueue.mu.Lock()
+			defer meta.provisioningQueue.mu.Unlock()
+			heldWhileClosing = heldWhileClosing || meta.provisioningQueue.active > 0
+		}
+
+		diags := resourceAWSAccountDelete(ctx, d, meta)
+		if tc.err != "" {
+			if !diags.HasError() || !strings.Contains(diags[0].Summary, tc.err) {
+				t.Fatalf("%s: expected error containing %q, got %v", tc.mode, tc.err, diags)
+			}
+			if _, ok := fake.provisionedProducts[ppID]; !ok {
+				t.Fatalf("%s: expected the provisioned account not to be terminated", tc.mode)
+			}
+			continue
+		}
+		if diags.HasError() {
+			t.Fatalf("%s: err: %v", tc.mode, diags)
+		}
+
+		_, provisioned := fake.provisionedProducts[ppID]
+		if provisioned != (tc.mode == deletionModeRetain) {
+			t.Fatalf("%s: expected the provisioned account to exist: %t, got %t", tc.mode, tc.mode == deletionModeRetain, provisioned)
+		}
+
+		switch tc.mode {
+		case deletionModeRetain:
+			if parentID := fake.accountParent(accountID); parentID != workloads {
+				t.Fatalf("%s: expected the account to stay in %s, got %s", tc.mode, workloads, parentID)
+			}
+		case deletionModeMoveToOU:
+			if parentID := fake.accountParent(accountID); parentID != quarantine {
+				t.Fatalf("%s: expected the account to be moved to %s, got %s", tc.mode, quarantine, parentID)
+			}
+		}
+
+		if heldWhileClosing {
+			t.Fatalf("%s: expected the provisioning slot to be released while closing the account", tc.mode)
+		}
+
+		status := fake.accounts[accountID].status
+		if closed := status == organizations.AccountStatusSuspended; closed != (tc.mode == deletionModeClose) {
+			t.Fatalf("%s: unexpected account status %s", tc.mode, status)
+		}
+	}
+}
+
+func TestResourceAWSAccount_customizeDiffDeletion(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	fake.addOU(fakeRootID, "Workloads")
+	fake.addOU(fakeRootID, "Quarantine")
+	meta := fake.client(t)
+
+	cases := []struct {
+		mode string
+		path string
+		err  string
+	}{
+		{mode: deletionModeUnmanage},
+		{mode: deletionModeMoveToOU, path: "Quarantine"},
+		{mode: deletionModeMoveToOU, path: testUnknownValue},
+		{mode: deletionModeMoveToOU, err: "deletion_organizational_unit_path must be configured"},
+		{mode: deletionModeMoveToOU, path: "Quarantaine", err: "Invalid deletion_organizational_unit_path"},
+	}
+
+	for _, tc := range cases {
+		raw := testResourceAWSAccountRaw("Workloads")
+		raw["deletion_mode"] = tc.mode
+		if tc.path != "" {
+			raw["deletion_organizational_unit_path"] = tc.path
+		}
+
+		_, err := resourceAWSAccount().Diff(ctx, nil, terraform.NewResourceConfigRaw(raw), meta)
+		if tc.err == "" && err != nil {
+			t.Fatalf("%s %s: unexpected error: %s", tc.mode, tc.path, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Fatalf("%s %s: expected error containing %q, got %v", tc.mode, tc.path, tc.err, err)
+		}
+	}
+}
+
+func TestFindProvisionedAccount(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	fake.addOU(fakeRootID, "Workloads")
+	fake.addProduct("Custom Account Factory", &fakeArtifact{id: "pa-custom", name: "v1", active: true})
+	meta := fake.client(t)
+
+	var ppIDs, accountIDs []string
+	for _, name := range []string{"default", "custom", "failing"} {
+		raw := testResourceAWSAccountRaw("Workloads")
+		raw["name"] = name
+		raw["email"] = name + "@example.com"
+		if name == "custom" {
+			raw["product_name"] = "Custom Account Factory"
+		}
+
+		d := testResourceDataWithChanges(t, resourceAWSAccount(), nil, raw, meta)
+		if diags := resourceAWSAccountCreate(ctx, d, meta); diags.HasError() {
+			t.Fatalf("err: %v", diags)
+		}
+		ppIDs = append(ppIDs, d.Id())
+		accountIDs = append(accountIDs, d.Get("account_id").(string))
+	}
+	fake.errors["GetProvisionedProductOutputs/"+ppIDs[2]] = servicecatalog.ErrCodeResourceNotFoundException
+
+	// Accounts vended by other products than the Account Factory should be found.
+	for _, value := range []string{accountIDs[1], "CUSTOM@example.com"} {
+		ppID, err := findProvisionedAccount(ctx, meta.AWSClient.scconn, meta.AWSClient.scLimiter, value)
+		if err != nil {
+			t.Fatalf("%s: err: %s", value, err)
+		}
+		if ppID != ppIDs[1] {
+			t.Fatalf("%s: expected provisioned product %s, got %s", value, ppIDs[1], ppID)
+		}
+	}
+
+	// Provisioned products whose outputs can't be read should be skipped.
+	if _, err := findProvisionedAccount(ctx, meta.AWSClient.scconn, meta.AWSClient.scLimiter, accountIDs[2]); !errors.Is(err, errNoProvisionedAccount) {
+		t.Fatalf("expected no provisioned account to be found, got %v", err)
+	}
+}
+
+func TestResourceAWSAccount_failedRecord(t *testing.T) {
+	fake := newFakeAWS(t)
+	fake.addOU(fakeRootID, "Workloads")
+	fake.failRecords["test"] = "Account email address is already in use"
+
+	d := testResourceAWSAccountData(t, "Workloads")
+
+	diags := resourceAWSAccountCreate(context.Background(), d, fake.client(t))
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "Account email address is already in use") {
+		t.Fatalf("expected provisioning to fail, got %v", diags)
+	}
+
+	// The ID must be set so the failed account is tainted and can be cleaned up.
+	if d.Id() == "" {
+		t.Fatalf("expected the ID of the failed provisioned product to be set")
+	}
+}
+
+func TestResourceAWSAccount_organizationalUnitNotFound(t *testing.T) {
+	fake := newFakeAWS(t)
+	fake.addOU(fakeRootID, "Workloads")
+
+	d := testResourceAWSAccountData(t, "Workloads/Missing")
+
+	diags := resourceAWSAccountCreate(context.Background(), d, fake.client(t))
+	if !diags.HasError() {
+		t.Fatalf("expected an error")
+	}
+	if !diags[0].AttributePath.Equals(cty.GetAttrPath("organizational_unit_path")) {
+		t.Fatalf("expected error for organizational_unit_path, got %#v", diags[0].AttributePath)
+	}
+}
+
+func testResourceAWSAccountData(t *testing.T, ouPath string) *schema.ResourceData {
+	return schema.TestResourceDataRaw(t, resourceAWSAccount().Schema, testResourceAWSAccountRaw(ouPath))
+}
+
+func testResourceAWSAccountRaw(ouPath string) map[string]interface{} {
+	raw := map[string]interface{}{
+		"name":  "test",
+		"email": "test@example.com",
+		"sso": []interface{}{
+			map[string]interface{}{
+				"firstname": "Control",
+				"lastname":  "Tower",
+				"email":     "control-tower@example.com",
+			},
+		},
+	}
+	if ouPath != "" {
+		raw["organizational_unit_path"] = ouPath
+	}
+
+	return raw
+}
+
+// testResourceDataWithChanges returns the resource data to update the resource from
+// the state to the raw configuration, like Terraform would during an apply.
+func testResourceDataWithChanges(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}, meta interface{}) *schema.ResourceData {
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	d, err := schema.InternalMap(r.Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return d
+}
+
+func testUnitCheckAWSAccountParent(fake *fakeAWS, resourceName, parentID string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+
+		accountID := rs.Primary.Attributes["account_id"]
+		if parent := fake.accountParent(accountID); parent != parentID {
+			return fmt.Errorf("expected account %s in %s, got %s", accountID, parentID, parent)
+		}
+
+		return nil
+	}
+}
+
+func testUnitCheckAWSAccountDestroy(fake *fakeAWS) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		for id := range fake.provisionedProducts {
+			return fmt.Errorf("provisioned product %s still exists", id)
+		}
+
+		return nil
+	}
+}
+
+func testUnitAWSAccountImportStateIdFunc(resourceName, attribute string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("resource not found: %s", resourceName)
+		}
+
+		return rs.Primary.Attributes[attribute], nil
+	}
+}
+
+func testUnitResourceAWSAccountConfig(ouPath string) string {
+	return fmt.Sprintf(`
+resource "mcaf_aws_account" "test" {
+  name                     = "test"
+  email                    = "test@example.com"
+  organizational_unit_path = %q
+
+  sso {
+    firstname = "Control"
+    lastname  = "Tower"
+    email     = "control-tower@example.com"
+  }
+}
+`, ouPath)
+}
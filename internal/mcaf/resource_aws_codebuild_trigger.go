@@ -1,19 +1,20 @@
 package mcaf
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func resourceAWSCodeBuildTrigger() *schema.Resource {
 	return &schema.Resource{
-		Create: checkProvider("aws", resourceAWSCodeBuildTriggerCreate),
-		Read:   checkProvider("aws", resourceAWSCodeBuildTriggerRead),
-		Update: checkProvider("aws", resourceAWSCodeBuildTriggerUpdate),
-		Delete: checkProvider("aws", resourceAWSCodeBuildTriggerDelete),
+		CreateContext: checkProvider("aws", resourceAWSCodeBuildTriggerCreate),
+		ReadContext:   checkProvider("aws", resourceAWSCodeBuildTriggerRead),
+		UpdateContext: checkProvider("aws", resourceAWSCodeBuildTriggerUpdate),
+		DeleteContext: checkProvider("aws", resourceAWSCodeBuildTriggerDelete),
 
 		Schema: map[string]*schema.Schema{
 			"project": {
@@ -33,30 +34,30 @@ func resourceAWSCodeBuildTrigger() *schema.Resource {
 	}
 }
 
-func resourceAWSCodeBuildTriggerCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceAWSCodeBuildTriggerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// Set the ID.
 	d.SetId(d.Get("project").(string))
 
 	// Trigger the CodeBuild pipeline.
-	return triggerCodeBuildPipeline(d, meta)
+	return triggerCodeBuildPipeline(ctx, d, meta)
 }
 
-func resourceAWSCodeBuildTriggerRead(d *schema.ResourceData, meta interface{}) error {
+func resourceAWSCodeBuildTriggerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// There isn't anything to read back.
 	return nil
 }
 
-func resourceAWSCodeBuildTriggerUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceAWSCodeBuildTriggerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// Trigger the CodeBuild pipeline.
-	return triggerCodeBuildPipeline(d, meta)
+	return triggerCodeBuildPipeline(ctx, d, meta)
 }
 
-func resourceAWSCodeBuildTriggerDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceAWSCodeBuildTriggerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// There isn't anything to delete.
 	return nil
 }
 
-func triggerCodeBuildPipeline(d *schema.ResourceData, meta interface{}) error {
+func triggerCodeBuildPipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	cbconn := meta.(*Client).AWSClient.cbconn
 
 	// Define the required input parameters.
@@ -66,8 +67,8 @@ func triggerCodeBuildPipeline(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	// Trigger all pipelines by starting a new build.
-	if _, err := cbconn.StartBuild(input); err != nil {
-		return fmt.Errorf("Failed to start new build: %v", err)
+	if _, err := cbconn.StartBuildWithContext(ctx, input); err != nil {
+		return diag.Errorf("Failed to start new build: %v", err)
 	}
 
 	return nil
@@ -0,0 +1,56 @@
+package mcaf
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitResourceAWSCodeBuildTrigger_basic(t *testing.T) {
+	resourceName := "mcaf_aws_codebuild_trigger.test"
+
+	fake := newFakeAWS(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testUnitPreCheck(t)
+		},
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fake.providerConfig() + testUnitResourceAWSCodeBuildTriggerConfig("v0.1.0"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "id", "test"),
+					testUnitCheckAWSCodeBuildBuilds(fake, 1),
+				),
+			},
+			{
+				Config: fake.providerConfig() + testUnitResourceAWSCodeBuildTriggerConfig("v0.2.0"),
+				Check: resource.ComposeTestCheckFunc(
+					testUnitCheckAWSCodeBuildBuilds(fake, 2),
+				),
+			},
+		},
+	})
+}
+
+func testUnitCheckAWSCodeBuildBuilds(fake *fakeAWS, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if builds := fake.buildCount(); builds != expected {
+			return fmt.Errorf("expected %d builds, got %d", expected, builds)
+		}
+		return nil
+	}
+}
+
+func testUnitResourceAWSCodeBuildTriggerConfig(version string) string {
+	return fmt.Sprintf(`
+resource "mcaf_aws_codebuild_trigger" "test" {
+  project    = "test"
+  release_id = "e58df79"
+  version    = %q
+}
+`, version)
+}
@@ -0,0 +1,434 @@
+package mcaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/hashicorp/aws-sdk-go-base/tfawserr"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceAWSOrganizationalUnit() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: checkProvider("aws", resourceAWSOrganizationalUnitCreate),
+		ReadContext:   checkProvider("aws", resourceAWSOrganizationalUnitRead),
+		UpdateContext: checkProvider("aws", resourceAWSOrganizationalUnitUpdate),
+		DeleteContext: checkProvider("aws", resourceAWSOrganizationalUnitDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceAWSOrganizationalUnitImport,
+		},
+
+		CustomizeDiff: customdiff.All(
+			resourceAWSOrganizationalUnitCustomizeDiff,
+			customizeDiffTags,
+		),
+
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validateOrganizationalUnitPath,
+				DiffSuppressFunc: suppressEquivalentOrganizationalUnitPath,
+			},
+			"create_parents": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"force_destroy": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"tags": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"tags_all": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"arn": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"parent_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceAWSOrganizationalUnitCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*Client).AWSClient.orgsconn
+
+	roots, err := listRoots(ctx, conn)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	path := normalizeOrganizationalUnitPath(d.Get("path").(string))
+	if path == "" {
+		return diag.Errorf("Invalid organizational unit path %q: the path must contain at least one organizational unit", d.Get("path").(string))
+	}
+
+	segments := strings.Split(path, "/")
+	name := segments[len(segments)-1]
+
+	// Resolve the parent OU, creating any missing parents when configured.
+	parentID, err := ensureOrganizationalUnitParents(ctx, conn, segments[:len(segments)-1], aws.StringValue(roots[0].Id), d.Get("create_parents").(bool))
+	if err != nil {
+		return diag.Errorf("Error resolving the parent of organizational unit %s: %v", path, err)
+	}
+
+	log.Printf("[DEBUG] Create organizational unit %s in parent %s", path, parentID)
+	output, err := conn.CreateOrganizationalUnitWithContext(ctx, &organizations.CreateOrganizationalUnitInput{
+		Name:     aws.String(name),
+		ParentId: aws.String(parentID),
+		Tags:     expandOrganizationsTags(d.Get("tags_all").(map[string]interface{})),
+	})
+	if tfawserr.ErrCodeEquals(err, organizations.ErrCodeDuplicateOrganizationalUnitException) {
+		return diag.Errorf("Error creating organizational unit %s: it already exists, import it to manage it using Terraform", path)
+	}
+	if err != nil {
+		return diag.Errorf("Error creating organizational unit %s: %v", path, err)
+	}
+
+	d.SetId(aws.StringValue(output.OrganizationalUnit.Id))
+
+	return resourceAWSOrganizationalUnitRead(ctx, d, meta)
+}
+
+func resourceAWSOrganizationalUnitRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*Client).AWSClient.orgsconn
+
+	output, err := conn.DescribeOrganizationalUnitWithContext(ctx, &organizations.DescribeOrganizationalUnitInput{
+		OrganizationalUnitId: aws.String(d.Id()),
+	})
+	if tfawserr.ErrCodeEquals(err, organizations.ErrCodeOrganizationalUnitNotFoundException) {
+		log.Printf("[WARN] Organizational unit %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("Error reading organizational unit %s: %v", d.Id(), err)
+	}
+
+	// accountOrganizationalUnitPath returns the path of the parent of any child.
+	parentPath, err := accountOrganizationalUnitPath(ctx, conn, d.Id())
+	if err != nil {
+		return diag.Errorf("Error reading path of organizational unit %s: %v", d.Id(), err)
+	}
+
+	ou, err := organizationalUnitWithParent(ctx, conn, output.OrganizationalUnit, parentPath)
+	if err != nil {
+		return diag.Errorf("Error reading organizational unit %s: %v", d.Id(), err)
+	}
+
+	tags, err := listTags(ctx, conn, d.Id())
+	if err != nil {
+		return diag.Errorf("Error reading organizational unit %s: %v", d.Id(), err)
+	}
+
+	d.Set("arn", ou.OrganizationalUnit.Arn)
+	d.Set("name", ou.OrganizationalUnit.Name)
+	d.Set("parent_id", ou.ParentId)
+	d.Set("path", ou.Path)
+
+	if err := d.Set("tags", tagsWithoutDefaults(tags, d.Get("tags").(map[string]interface{}), meta.(*Client).AWSClient.defaultTags)); err != nil {
+		return diag.Errorf("Error setting tags: %s", err)
+	}
+	if err := d.Set("tags_all", tags); err != nil {
+		return diag.Errorf("Error setting tags_all: %s", err)
+	}
+
+	return nil
+}
+
+func resourceAWSOrganizationalUnitUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*Client).AWSClient.orgsconn
+
+	// Moving to another parent forces a new resource, so only the name can change.
+	if d.HasChange("path") {
+		segments := strings.Split(normalizeOrganizationalUnitPath(d.Get("path").(string)), "/")
+		name := segments[len(segments)-1]
+
+		log.Printf("[DEBUG] Rename organizational unit %s to %s", d.Id(), name)
+		_, err := conn.UpdateOrganizationalUnitWithContext(ctx, &organizations.UpdateOrganizationalUnitInput{
+			Name:                 aws.String(name),
+			OrganizationalUnitId: aws.String(d.Id()),
+		})
+		if err != nil {
+			return diag.Errorf("Error renaming organizational unit %s: %v", d.Id(), err)
+		}
+	}
+
+	if d.HasChange("tags_all") {
+		o, n := d.GetChange("tags_all")
+		if err := updateOrganizationsTags(ctx, conn, d.Id(), o.(map[string]interface{}), n.(map[string]interface{})); err != nil {
+			return diag.Errorf("Error updating tags of organizational unit %s: %v", d.Id(), err)
+		}
+	}
+
+	return resourceAWSOrganizationalUnitRead(ctx, d, meta)
+}
+
+func resourceAWSOrganizationalUnitDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*Client).AWSClient.orgsconn
+
+	var accounts []*organizations.Account
+	err := conn.ListAccountsForParentPagesWithContext(ctx, &organizations.ListAccountsForParentInput{
+		ParentId: aws.String(d.Id()),
+	}, func(page *organizations.ListAccountsForParentOutput, lastPage bool) bool {
+		accounts = append(accounts, page.Accounts...)
+		return !lastPage
+	})
+	if err != nil {
+		return diag.Errorf("Error listing accounts in organizational unit %s: %v", d.Id(), err)
+	}
+
+	if len(accounts) > 0 && !d.Get("force_destroy").(bool) {
+		return diag.Errorf("Error deleting organizational unit %s: it still contains %d accounts, "+
+			"move the accounts to another organizational unit or set force_destroy to move them to the parent", d.Id(), len(accounts))
+	}
+
+	parentID := d.Get("parent_id").(string)
+	for _, account := range accounts {
+		log.Printf("[DEBUG] Move account %s from organizational unit %s to %s", aws.StringValue(account.Id), d.Id(), parentID)
+		_, err := conn.MoveAccountWithContext(ctx, &organizations.MoveAccountInput{
+			AccountId:           account.Id,
+			DestinationParentId: aws.String(parentID),
+			SourceParentId:      aws.String(d.Id()),
+		})
+		if err != nil {
+			return diag.Errorf("Error moving account %s to %s: %v", aws.StringValue(account.Id), parentID, err)
+		}
+	}
+
+	log.Printf("[DEBUG] Delete organizational unit %s", d.Id())
+	_, err = conn.DeleteOrganizationalUnitWithContext(ctx, &organizations.DeleteOrganizationalUnitInput{
+		OrganizationalUnitId: aws.String(d.Id()),
+	})
+	if tfawserr.ErrCodeEquals(err, organizations.ErrCodeOrganizationalUnitNotFoundException) {
+		return nil
+	}
+	if err != nil {
+		return diag.Errorf("Error deleting organizational unit %s: %v", d.Id(), err)
+	}
+
+	return nil
+}
+
+func resourceAWSOrganizationalUnitImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	conn := meta.(*Client).AWSClient.orgsconn
+
+	// The import ID can be an organizational unit ID or path.
+	importID := d.Id()
+	if strings.HasPrefix(importID, "ou-") {
+		setOrganizationalUnitDefaults(d)
+		return []*schema.ResourceData{d}, nil
+	}
+
+	roots, err := listRoots(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	ou, err := findOrganizationalUnitByPath(ctx, conn, importID, aws.StringValue(roots[0].Id), "Root", false)
+	if err != nil {
+		return nil, fmt.Errorf("Error importing organizational unit %s: %v", importID, err)
+	}
+
+	log.Printf("[DEBUG] Import organizational unit %s: %s", importID, aws.StringValue(ou.OrganizationalUnit.Id))
+	d.SetId(aws.StringValue(ou.OrganizationalUnit.Id))
+	setOrganizationalUnitDefaults(d)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// setOrganizationalUnitDefaults sets the arguments that only exist in Terraform to their
+// defaults, so an imported organizational unit doesn't show a diff for them.
+func setOrganizationalUnitDefaults(d *schema.ResourceData) {
+	for k, v := range resourceAWSOrganizationalUnit().Schema {
+		if v.Default != nil {
+			d.Set(k, v.Default)
+		}
+	}
+}
+
+// resourceAWSOrganizationalUnitCustomizeDiff forces a new resource when the parent
+// of the organizational unit changes, as organizational units can't be moved.
+func resourceAWSOrganizationalUnitCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.HasChange("path") {
+		return nil
+	}
+
+	o, n := d.GetChange("path")
+	if organizationalUnitParentPath(o.(string)) != organizationalUnitParentPath(n.(string)) {
+		return d.ForceNew("path")
+	}
+
+	return nil
+}
+
+// organizationalUnitParentPath returns the normalized path of the parent of the OU path.
+func organizationalUnitParentPath(path string) string {
+	path = normalizeOrganizationalUnitPath(path)
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+
+	return ""
+}
+
+// ensureOrganizationalUnitParents resolves the OU names under the root to the ID of
+// the last OU, creating any missing OUs when create is true.
+func ensureOrganizationalUnitParents(ctx context.Context, conn organizationsAPI, names []string, rootID string, create bool) (string, error) {
+	parentID := rootID
+	parentPath := "Root"
+
+	// Resolve the longest path of existing parents, the remaining parents are created.
+	existing := len(names)
+	for ; existing > 0; existing-- {
+		ou, err := findOrganizationalUnitByPath(ctx, conn, strings.Join(names[:existing], "/"), rootID, "Root", false)
+		if err == nil {
+			parentID = aws.StringValue(ou.OrganizationalUnit.Id)
+			parentPath = aws.StringValue(ou.Path)
+			break
+		}
+
+		var notFoundErr *organizationalUnitNotFoundError
+		if !create || !errors.As(err, &notFoundErr) {
+			return "", err
+		}
+	}
+
+	for _, name := range names[existing:] {
+		log.Printf("[DEBUG] Create missing parent organizational unit %s/%s", parentPath, name)
+		output, err := conn.CreateOrganizationalUnitWithContext(ctx, &organizations.CreateOrganizationalUnitInput{
+			Name:     aws.String(name),
+			ParentId: aws.String(parentID),
+		})
+		if err != nil {
+			return "", fmt.Errorf("error creating organizational unit %s/%s: %v", parentPath, name, err)
+		}
+
+		parentID = aws.StringValue(output.OrganizationalUnit.Id)
+		parentPath = parentPath + "/" + name
+	}
+
+	return parentID, nil
+}
+
+// expandOrganizationsTags converts the tags map to Organizations tags.
+func expandOrganizationsTags(m map[string]interface{}) []*organizations.Tag {
+	var tags []*organizations.Tag
+	for k, v := range m {
+		tags = append(tags, &organizations.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(v.(string)),
+		})
+	}
+
+	return tags
+}
+
+// updateOrganizationsTags updates the tags of the Organizations resource from the old to the new tags.
+func updateOrganizationsTags(ctx context.Context, conn organizationsAPI, resourceID string, o, n map[string]interface{}) error {
+	var removed []*string
+	for k := range o {
+		if _, ok := n[k]; !ok {
+			removed = append(removed, aws.String(k))
+		}
+	}
+
+	if len(removed) > 0 {
+		log.Printf("[DEBUG] Remove tags from %s: %v", resourceID, aws.StringValueSlice(removed))
+		_, err := conn.UntagResourceWithContext(ctx, &organizations.UntagResourceInput{
+			ResourceId: aws.String(resourceID),
+			TagKeys:    removed,
+		})
+		if err != nil {
+			return fmt.Errorf("error removing tags: %v", err)
+		}
+	}
+
+	updated := make(map[string]interface{})
+	for k, v := range n {
+		if old, ok := o[k]; !ok || old != v {
+			updated[k] = v
+		}
+	}
+
+	if len(updated) > 0 {
+		log.Printf("[DEBUG] Update tags of %s", resourceID)
+		_, err := conn.TagResourceWithContext(ctx, &organizations.TagResourceInput{
+			ResourceId: aws.String(resourceID),
+			Tags:       expandOrganizationsTags(updated),
+		})
+		if err != nil {
+			return fmt.Errorf("error updating tags: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// customizeDiffTags plans the tags_all of a resource supporting tags, being the default
+// tags of the provider merged with the tags of the resource.
+func customizeDiffTags(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("tags") {
+		return d.SetNewComputed("tags_all")
+	}
+
+	var defaultTags map[string]string
+	if client := meta.(*Client).AWSClient; client != nil {
+		defaultTags = client.defaultTags
+	}
+
+	tags := make(map[string]interface{})
+	for k, v := range defaultTags {
+		tags[k] = v
+	}
+	for k, v := range d.Get("tags").(map[string]interface{}) {
+		tags[k] = v
+	}
+
+	if !reflect.DeepEqual(tags, d.Get("tags_all").(map[string]interface{})) {
+		return d.SetNew("tags_all", tags)
+	}
+
+	return nil
+}
+
+// tagsWithoutDefaults returns the tags of a resource without the default tags, unless
+// they are configured on the resource as well.
+func tagsWithoutDefaults(tags map[string]string, configured map[string]interface{}, defaultTags map[string]string) map[string]string {
+	result := make(map[string]string)
+	for k, v := range tags {
+		if _, ok := configured[k]; !ok {
+			if dv, ok := defaultTags[k]; ok && dv == v {
+				continue
+			}
+		}
+		result[k] = v
+	}
+
+	return result
+}
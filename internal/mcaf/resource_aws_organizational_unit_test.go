@@ -0,0 +1,252 @@
+package mcaf
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestUnitResourceAWSOrganizationalUnit_basic(t *testing.T) {
+	resourceName := "mcaf_aws_organizational_unit.test"
+
+	fake := newFakeAWS(t)
+	fake.addOU(fakeRootID, "Workloads")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck: func() {
+			testUnitPreCheck(t)
+		},
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testUnitCheckAWSOrganizationalUnitDestroy(fake, "Prod", "Production"),
+		Steps: []resource.TestStep{
+			{
+				Config: fake.providerConfig() + testUnitResourceAWSOrganizationalUnitConfig("Workloads/Prod", "production"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", "Prod"),
+					resource.TestCheckResourceAttr(resourceName, "tags.Environment", "production"),
+				),
+			},
+			{
+				Config: fake.providerConfig() + testUnitResourceAWSOrganizationalUnitConfig("Root/Workloads/Production", "prod"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", "Production"),
+					resource.TestCheckResourceAttr(resourceName, "tags.Environment", "prod"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     "Root/Workloads/Production",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceAWSOrganizationalUnit_lifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	meta := fake.client(t)
+
+	// Missing parents are only created when configured.
+	d := testResourceAWSOrganizationalUnitData(t, "Root/Workloads/Prod", false, meta)
+	if diags := resourceAWSOrganizationalUnitCreate(ctx, d, meta); !diags.HasError() || !strings.Contains(diags[0].Summary, "Workloads not found") {
+		t.Fatalf("expected the parent not to be found, got %v", diags)
+	}
+
+	d = testResourceAWSOrganizationalUnitData(t, "Root/Workloads/Prod", true, meta)
+	if diags := resourceAWSOrganizationalUnitCreate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if v := d.Get("path").(string); v != "Root/Workloads/Prod" {
+		t.Fatalf("expected path Root/Workloads/Prod, got %q", v)
+	}
+	if v := d.Get("tags.Environment").(string); v != "production" {
+		t.Fatalf("expected Environment tag production, got %q", v)
+	}
+
+	workloads := d.Get("parent_id").(string)
+	accountID := fake.addAccount(d.Id(), "prod", "prod@example.com")
+
+	// Only the missing parents should be created.
+	team := testResourceAWSOrganizationalUnitData(t, "Workloads/Dev/Team", true, meta)
+	if diags := resourceAWSOrganizationalUnitCreate(ctx, team, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if v := team.Get("path").(string); v != "Root/Workloads/Dev/Team" {
+		t.Fatalf("expected path Root/Workloads/Dev/Team, got %q", v)
+	}
+	dev, err := findOrganizationalUnitByPath(ctx, meta.AWSClient.orgsconn, "Workloads/Dev", fakeRootID, "Root", false)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if parent := aws.StringValue(dev.ParentId); parent != workloads {
+		t.Fatalf("expected Dev to be created in %s, got %s", workloads, parent)
+	}
+
+	// Creating an existing OU should fail.
+	existing := testResourceAWSOrganizationalUnitData(t, "Workloads/Prod", false, meta)
+	if diags := resourceAWSOrganizationalUnitCreate(ctx, existing, meta); !diags.HasError() || !strings.Contains(diags[0].Summary, "already exists") {
+		t.Fatalf("expected the OU to exist, got %v", diags)
+	}
+
+	// Importing by path should resolve the ID.
+	imported := resourceAWSOrganizationalUnit().Data(nil)
+	imported.SetId("Workloads/Prod")
+	if _, err := resourceAWSOrganizationalUnitImport(ctx, imported, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if imported.Id() != d.Id() {
+		t.Fatalf("expected import to resolve to %s, got %s", d.Id(), imported.Id())
+	}
+	for _, k := range []string{"create_parents", "force_destroy"} {
+		if v, ok := imported.State().Attributes[k]; !ok || v != "false" {
+			t.Fatalf("expected %s to be imported as false, got %q", k, v)
+		}
+	}
+
+	// OUs containing accounts are only deleted when forced.
+	if diags := resourceAWSOrganizationalUnitDelete(ctx, d, meta); !diags.HasError() || !strings.Contains(diags[0].Summary, "still contains 1 accounts") {
+		t.Fatalf("expected the OU to contain accounts, got %v", diags)
+	}
+
+	d.Set("force_destroy", true)
+	if diags := resourceAWSOrganizationalUnitDelete(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if parent := fake.accountParent(accountID); parent != workloads {
+		t.Fatalf("expected account to be moved to %s, got %s", workloads, parent)
+	}
+
+	if diags := resourceAWSOrganizationalUnitRead(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected OU to be removed from the state")
+	}
+}
+
+func TestResourceAWSOrganizationalUnit_defaultTags(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakeAWS(t)
+	meta := fake.client(t)
+	meta.AWSClient.defaultTags = map[string]string{"Environment": "default", "Owner": "platform"}
+
+	d := testResourceAWSOrganizationalUnitData(t, "Workloads", false, meta)
+	if diags := resourceAWSOrganizationalUnitCreate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	expected := map[string]string{"Environment": "production", "Owner": "platform"}
+	if !reflect.DeepEqual(fake.tags[d.Id()], expected) {
+		t.Fatalf("expected tags %v, got %v", expected, fake.tags[d.Id()])
+	}
+	if v := d.Get("tags").(map[string]interface{}); len(v) != 1 {
+		t.Fatalf("expected 1 tag without the default tags, got %v", v)
+	}
+	if v := d.Get("tags_all").(map[string]interface{}); len(v) != 2 {
+		t.Fatalf("expected 2 tags including the default tags, got %v", v)
+	}
+
+	// Removing the tag of the resource should fall back to the default tag.
+	d = testResourceDataWithChanges(t, resourceAWSOrganizationalUnit(), d.State(), map[string]interface{}{"path": "Workloads"}, meta)
+	if diags := resourceAWSOrganizationalUnitUpdate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("err: %v", diags)
+	}
+
+	expected = map[string]string{"Environment": "default", "Owner": "platform"}
+	if !reflect.DeepEqual(fake.tags[d.Id()], expected) {
+		t.Fatalf("expected tags %v, got %v", expected, fake.tags[d.Id()])
+	}
+	if v := d.Get("tags").(map[string]interface{}); len(v) != 0 {
+		t.Fatalf("expected no tags without the default tags, got %v", v)
+	}
+}
+
+func TestResourceAWSOrganizationalUnit_validation(t *testing.T) {
+	cases := map[string]bool{
+		"Root/Workloads/Prod": true,
+		"Workloads":           true,
+		"Root":                false,
+		"root/":               false,
+		"Workloads//Prod":     false,
+		" ":                   false,
+	}
+
+	for path, valid := range cases {
+		diags := resourceAWSOrganizationalUnit().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{"path": path}))
+		if valid && diags.HasError() {
+			t.Fatalf("%q: unexpected errors: %v", path, diags)
+		}
+		if !valid && !diags.HasError() {
+			t.Fatalf("%q: expected an error", path)
+		}
+	}
+}
+
+func TestOrganizationalUnitParentPath(t *testing.T) {
+	cases := map[string]string{
+		"Root/Workloads/Prod": "Workloads",
+		"Workloads/Prod":      "Workloads",
+		"Root/Workloads":      "",
+		"Workloads":           "",
+	}
+
+	for path, expected := range cases {
+		if got := organizationalUnitParentPath(path); got != expected {
+			t.Errorf("expected parent of %s to be %q, got %q", path, expected, got)
+		}
+	}
+}
+
+func testResourceAWSOrganizationalUnitData(t *testing.T, path string, createParents bool, meta interface{}) *schema.ResourceData {
+	raw := map[string]interface{}{
+		"create_parents": createParents,
+		"tags": map[string]interface{}{
+			"Environment": "production",
+		},
+	}
+	if path != "" {
+		raw["path"] = path
+	}
+
+	return testResourceDataWithChanges(t, resourceAWSOrganizationalUnit(), nil, raw, meta)
+}
+
+func testUnitCheckAWSOrganizationalUnitDestroy(fake *fakeAWS, names ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		for _, ou := range fake.ous {
+			for _, name := range names {
+				if ou.name == name {
+					return fmt.Errorf("organizational unit %s still exists", ou.id)
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+func testUnitResourceAWSOrganizationalUnitConfig(path, environment string) string {
+	return fmt.Sprintf(`
+resource "mcaf_aws_organizational_unit" "test" {
+  path = %q
+
+  tags = {
+    Environment = %q
+  }
+}
+`, path, environment)
+}